@@ -1,12 +1,12 @@
 // Package config provides flexible access to config variables by priority:
 // flags - HI,
-// environment variables - MID,
-// default values defined with a struct field tags - LOW
+// environment variables (and dotenv files) - MID,
+// config files - LOW,
+// default values defined with a struct field tags - LOWEST
 package config
 
 import (
 	"errors"
-	"flag"
 	"fmt"
 	"os"
 	"reflect"
@@ -22,15 +22,75 @@ var (
 	errCantSet = errors.New("Value can not be set")
 	// trying to assign the value to unsupported field type
 	errUnsupportedType = func(typeName string) error {
-		return fmt.Errorf("Unsupported type [%s] in config", typeName)
+		return &UnsupportedTypeError{Type: typeName}
 	}
 	// value parse error
 	errCantUse = func(val string, typ interface{}) error {
-		return fmt.Errorf("cannot use [%s] as type [%T]", val, typ)
+		return &ParseError{Value: val, Type: fmt.Sprintf("%T", typ)}
+	}
+	// format of the config file can not be inferred from its extension
+	errUnknownFormat = func(file string) error {
+		return &FileError{File: file, Err: errors.New("unknown config file format")}
+	}
+	// config field uses the name reserved for the config file flag
+	errReservedFlag = func(name string) error {
+		return fmt.Errorf("flag [--%s] is reserved for the config file", name)
+	}
+	// config file can not be decoded
+	errCantDecode = func(file, format string, err error) error {
+		return &FileError{File: file, Err: fmt.Errorf("cannot decode %s file: %w", format, err)}
+	}
+	// config file contains a key that does not match any config field
+	errUnknownKey = func(file, path string) error {
+		return &FileError{File: file, Key: path, Err: fmt.Errorf("unknown key [%s]", path)}
+	}
+	// config file value does not match the type of config field
+	errTypeMismatch = func(field Field, val interface{}, typ string) error {
+		return &ParseError{Field: field, Value: fmt.Sprint(val), Type: typ}
+	}
+	// dotenv file syntax error
+	errDotEnvSyntax = func(file string, line int, msg string) error {
+		return &FileError{File: file, Err: fmt.Errorf("line %d: %s", line, msg)}
+	}
+	// flag parse error
+	errInvalidFlag = func(err error) error {
+		return fmt.Errorf("invalid flag: %w", err)
+	}
+	// validation tag can not be used with the field
+	errInvalidRule = func(rule, param string, typ reflect.Type) error {
+		return fmt.Errorf("invalid [%s:%q] tag for type [%s]", rule, param, typ)
+	}
+	// invalid value of the tag
+	errInvalidTag = func(tag, value string) error {
+		return fmt.Errorf("invalid [%s:%q] tag", tag, value)
+	}
+	// map item without "=" separator
+	errInvalidMapItem = func(item string) error {
+		return fmt.Errorf("invalid map item [%s], expected key=value", item)
+	}
+	// different fields with the same flag, env variable or key of config files
+	errNameCollision = func(name, other string) error {
+		return fmt.Errorf("%s collides with field [%s]", name, other)
+	}
+	// indexed env variable or flag skips elements of the slice
+	errSliceGap = func(name string, next int) error {
+		return fmt.Errorf("[%s] skips slice elements, the next index is [%d]", name, next)
+	}
+	// generic cause of the parse error of the secret field
+	errInvalidValue = func(typ string) error {
+		return fmt.Errorf("invalid %s value", typ)
+	}
+	// the rule refers to the field that does not exist
+	errUnknownField = func(rule, name string) error {
+		return fmt.Errorf("[%s] tag refers to unknown field [%s]", rule, name)
+	}
+	// the value does not fit into the type
+	errOutOfRange = func(val string, t interface{}, bitSize int) error {
+		return &ParseError{Value: val, Type: fmt.Sprintf("%T", t), Err: fmt.Errorf("value out of range (%d-bit)", bitSize)}
 	}
 	// missing required argument/flag
-	errMissingRequired = func(name string) error {
-		return fmt.Errorf("missing required [--%s] argument/flag", name)
+	errMissingRequired = func(field Field) error {
+		return &MissingRequiredError{Field: field}
 	}
 )
 
@@ -42,6 +102,43 @@ const (
 	keyIsRequired = "required"
 	// keyEnvVar - tag name for env variable name
 	keyEnvTag = "env"
+	// keyUsageTag and keyDescTag - tag names for the description of the field
+	// (used in the usage text)
+	keyUsageTag = "usage"
+	keyDescTag  = "desc"
+	// keyOneOfTag - tag name for space-separated list of allowed values
+	keyOneOfTag = "oneof"
+	// keyMinTag and keyMaxTag - tag names for min and max numeric values
+	keyMinTag = "min"
+	keyMaxTag = "max"
+	// keyMinLenTag and keyMaxLenTag - tag names for min and max length of
+	// strings and slices
+	keyMinLenTag = "minlen"
+	keyMaxLenTag = "maxlen"
+	// keyPatternTag - tag name for the regular expression the string should match
+	keyPatternTag = "pattern"
+	// keyRequiredIfTag - tag name for "Field value" condition the value is
+	// required on
+	keyRequiredIfTag = "required_if"
+	// keyRequiredWithTag - tag name for space-separated list of fields the value
+	// is required with
+	keyRequiredWithTag = "required_with"
+	// keyExclusiveTag - tag name for the group of mutually exclusive fields
+	keyExclusiveTag = "mutually_exclusive"
+	// keyAllowEmptyTag - tag name for the flag that allows empty env variables
+	keyAllowEmptyTag = "allowempty"
+	// keyConfigTag - tag name for config options of the field, "-" value
+	// excludes the field from loading (e.g. runtime-only fields)
+	keyConfigTag = "config"
+	// keySquashTag - tag name for the flag that defines if the nested struct is
+	// flattened into the parent namespace
+	keySquashTag = "squash"
+	// keyEnvScanTag - tag name for the flag that enables collecting map items
+	// from env variables with the prefix
+	keyEnvScanTag = "envscan"
+	// keySecretTag should have any non-empty value if the value is a secret
+	// (it is masked in the report and the dump)
+	keySecretTag = "secret"
 	// keyFlagTag - tag name for variable flag.
 	// By default (if there is no tag "flag" for struct field) will have name:
 	// -structname-nestedstructname-varname
@@ -56,93 +153,150 @@ const (
 
 // EnvPrefix is a prefix in the beginning of environment variable name (used to
 // easily differentiate variables of your application).
+//
+// Deprecated: the prefix is passed to the Loader with WithEnvPrefix() option
+// (or as an argument of Init() func), this variable is not used anymore.
 var EnvPrefix string
 
-// command line arguments
+// command line arguments (used by default)
 var args = os.Args[1:]
 
-// required args/flags container
-var seen map[string]bool
-
 // Init config values.
 func Init(c interface{}, prefix string) error {
-	// check argument type (only pointer to struct is supported)
-	rv := reflect.ValueOf(c)
-	if rv.Kind() != reflect.Ptr || rv.IsNil() {
-		return errInvalidReceiver
-	}
-	// set custom "application" prefix (will be used to build ENV VAR names)
-	EnvPrefix = prefix
-	// init flagset
-	flagSet := NewFlagSet("config", flag.ContinueOnError)
-	// reset required list
-	seen = make(map[string]bool)
-	if err := initConfig(rv, flagSet, emptyPrefix); err != nil {
-		return err
-	}
-	// parse flags
-	if err := flagSet.Parse(args); err != nil {
-		return err
-	}
-	// mark as seen flags that have been set
-	flagSet.Visit(func(f *flag.Flag) { seen[f.Name] = true })
-	// find missing required values
-	for flagName, ok := range seen {
-		if !ok {
-			return errMissingRequired(flagName)
-		}
-	}
-	// success
-	return nil
+	return NewLoader(WithEnvPrefix(prefix)).Load(c)
 }
 
 // initConfig recursively loads parameters to Config struct, supports nested
-// anonymous structs.
-func initConfig(c reflect.Value, flagSet *FlagSet, prefix string) error {
+// anonymous structs. The prefix defines the names of flags, env variables and
+// keys of config files (embedded structs are flattened into the parent
+// namespace), the path is a Go path of the struct. Problems with particular
+// fields do not stop the process, they are collected to report all of them at
+// once.
+func (s *loadState) initConfig(c reflect.Value, prefix, goPath string) {
+	// nested structs are added first
+	defer s.addStruct(c, goPath)
 	c = reflect.Indirect(c)
-	if c.Kind() != reflect.Struct {
-		return errInvalidReceiver
-	}
 	for i := 0; i < c.NumField(); i++ {
-		var value string
-		field := c.Field(i)
-		if field.Kind() == reflect.Struct {
-			np := nestedPrefix(prefix, c.Type().Field(i).Name)
-			err := initConfig(field.Addr(), flagSet, np)
+		field, structField := c.Field(i), c.Type().Field(i)
+		if isIgnored(structField) {
+			continue
+		}
+		fieldPath := joinPath(goPath, structField.Name)
+		isStruct := field.Kind() == reflect.Struct && s.textType(field.Type()) == nil
+		if isStruct || s.isSection(field.Type()) && field.CanSet() {
+			squash, err := isSquashed(structField)
 			if err != nil {
-				return err
+				s.errs = append(s.errs, &FieldError{Field: Field{Path: fieldPath}, Err: err})
+				continue
+			}
+			nested := nestedPrefix(prefix, structField.Name)
+			if squash {
+				nested = prefix
+			}
+			if !isStruct {
+				s.initSection(field, nested, fieldPath)
+				continue
 			}
+			s.initConfig(field.Addr(), nested, fieldPath)
+			if structField.Anonymous {
+				s.embed(field.Addr(), c.Addr())
+			}
+			continue
+		}
+		if isStructSlice(field.Type()) && s.textType(field.Type()) == nil && field.CanSet() {
+			s.initSlice(field, nestedPrefix(prefix, structField.Name), fieldPath)
 			continue
 		}
+		path := strings.Fields(nestedPrefix(prefix, structField.Name))
+		f := &fieldInfo{
+			Field: Field{
+				Path: fieldPath,
+				Flag: flagName(structField, prefix, s.flagNaming),
+				Env:  envName(structField, s.envPrefix, prefix, s.envNaming),
+			},
+			typ:      structField.Type,
+			text:     s.textType(structField.Type),
+			rv:       field,
+			tag:      structField.Tag,
+			required: structField.Tag.Get(keyIsRequired) != "",
+			secret:   structField.Tag.Get(keySecretTag) != "",
+		}
+		if f.text == nil && s.isPointer(f.typ) {
+			// the type of the value the pointer leaf points to
+			f.text = s.textType(f.typ.Elem())
+		}
 		if !field.CanSet() {
-			return errCantSet
-		}
-		structField := c.Type().Field(i)
-		flgKey := flagName(structField, prefix)
-		// read "is required" field tag
-		if isRequired := structField.Tag.Get(keyIsRequired); isRequired != "" {
-			// init map cell with flgKey (set false because it was not seen yet)
-			seen[flgKey] = false
-		}
-		// getting value from "default" tag
-		defValue := structField.Tag.Get(keyDefaultTag)
-		if defValue != "" {
-			value = defValue
-			seen[flgKey] = true
-		}
-		// retrieve value from ENV variable
-		envValue := os.Getenv(envName(structField, prefix))
-		if envValue != "" {
-			value = envValue
-			seen[flgKey] = true
+			s.fail(f, errCantSet)
+			continue
+		}
+		if f.Flag == s.configFlag {
+			s.fail(f, errReservedFlag(f.Flag))
+			continue
+		}
+		// names are checked before the flag is defined (FlagSet panics on
+		// redefinition)
+		if err := s.addNames(f, strings.ToLower(strings.Join(s.keyNaming.path(path), "."))); err != nil {
+			s.fail(f, err)
+			continue
+		}
+		allowEmpty := s.allowEmptyEnv
+		if tagValue, ok := structField.Tag.Lookup(keyAllowEmptyTag); ok {
+			var err error
+			if allowEmpty, err = strconv.ParseBool(tagValue); err != nil {
+				s.fail(f, errInvalidTag(keyAllowEmptyTag, tagValue))
+				continue
+			}
+		}
+		s.fields = append(s.fields, f)
+		// getting value from "default" tag (or the value set before loading,
+		// e.g. with SetDefaults)
+		if defValue := structField.Tag.Get(keyDefaultTag); defValue != "" {
+			f.set(Field{Source: SourceDefault}, defValue)
+		} else if !field.IsZero() {
+			f.set(Field{Source: SourceDefault}, formatValue(field))
 		}
+		// retrieve value from config files
+		for _, file := range s.files {
+			fileValue, key, err := file.lookup(path, f)
+			if err != nil {
+				s.errs = append(s.errs, err)
+			} else if key != "" {
+				f.set(Field{Source: SourceFile, File: file.file, Key: key}, fileValue)
+			}
+		}
+		// retrieve value from dotenv files
+		if dotEnvValue, ok := s.dotEnv[f.Env]; ok && (dotEnvValue != "" || allowEmpty) {
+			f.set(Field{Source: SourceDotEnv, File: s.dotEnvOrigins[f.Env]}, dotEnvValue)
+		}
+		// retrieve value from ENV variable (empty value is ignored unless allowed)
+		if envValue, ok := s.lookupEnv(f.Env); ok && (envValue != "" || allowEmpty) {
+			f.set(Field{Source: SourceEnv}, envValue)
+		}
+		// the value set before loading is kept as is unless any layer
+		// overrides it (its string form may be lossy, e.g. []string{"a,b"})
+		prefilled := f.Source == SourceDefault && structField.Tag.Get(keyDefaultTag) == ""
 		// set value with a flag
-		err := setValue(field, flagSet, flgKey, value)
+		var err error
+		if s.isPointer(f.typ) {
+			err = s.setPointer(f, f.Source != "" && !prefilled)
+		} else if prefilled {
+			current := reflect.New(field.Type()).Elem()
+			current.Set(field)
+			err = s.setValue(field, s.flagSet, f.Flag, "")
+			field.Set(current)
+		} else {
+			err = s.setValue(field, s.flagSet, f.Flag, f.value)
+		}
+		// collect map items from ENV variables with the prefix
+		if err == nil && field.Kind() == reflect.Map && structField.Tag.Get(keyEnvScanTag) != "" {
+			err = s.scanEnv(f)
+		}
 		if err != nil {
-			return err
+			s.fail(f, err)
+			continue
 		}
+		s.wrapFlag(f)
 	}
-	return nil
 }
 
 // setValue casts string value and assigns it to the field of Config struct.
@@ -150,7 +304,7 @@ func setValue(field reflect.Value, flagSet *FlagSet, flgKey, value string) error
 	switch t := field.Interface().(type) {
 	case time.Duration:
 		val, err := time.ParseDuration(value)
-		if err != nil {
+		if err != nil && value != "" {
 			return errCantUse(value, t)
 		}
 		flagSet.DurationVar(field.Addr().Interface().(*time.Duration), flgKey, val, "")
@@ -220,10 +374,98 @@ func setValue(field reflect.Value, flagSet *FlagSet, flgKey, value string) error
 			return err
 		}
 		flagSet.ArrayFloat64Var(field.Addr().Interface().(*[]float64), flgKey, []float64(*arrFloat64), "")
+	case int8:
+		val, err := parseInt(value, 8, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Int8Var(field.Addr().Interface().(*int8), flgKey, int8(val), "")
+	case []int8:
+		arrInt8 := new(arrayInt8)
+		if err := arrInt8.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayInt8Var(field.Addr().Interface().(*[]int8), flgKey, []int8(*arrInt8), "")
+	case int16:
+		val, err := parseInt(value, 16, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Int16Var(field.Addr().Interface().(*int16), flgKey, int16(val), "")
+	case []int16:
+		arrInt16 := new(arrayInt16)
+		if err := arrInt16.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayInt16Var(field.Addr().Interface().(*[]int16), flgKey, []int16(*arrInt16), "")
+	case int32:
+		val, err := parseInt(value, 32, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Int32Var(field.Addr().Interface().(*int32), flgKey, int32(val), "")
+	case []int32:
+		arrInt32 := new(arrayInt32)
+		if err := arrInt32.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayInt32Var(field.Addr().Interface().(*[]int32), flgKey, []int32(*arrInt32), "")
+	case uint8:
+		val, err := parseUint(value, 8, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Uint8Var(field.Addr().Interface().(*uint8), flgKey, uint8(val), "")
+	case []uint8:
+		arrUint8 := new(arrayUint8)
+		if err := arrUint8.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayUint8Var(field.Addr().Interface().(*[]uint8), flgKey, []uint8(*arrUint8), "")
+	case uint16:
+		val, err := parseUint(value, 16, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Uint16Var(field.Addr().Interface().(*uint16), flgKey, uint16(val), "")
+	case []uint16:
+		arrUint16 := new(arrayUint16)
+		if err := arrUint16.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayUint16Var(field.Addr().Interface().(*[]uint16), flgKey, []uint16(*arrUint16), "")
+	case uint32:
+		val, err := parseUint(value, 32, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Uint32Var(field.Addr().Interface().(*uint32), flgKey, uint32(val), "")
+	case []uint32:
+		arrUint32 := new(arrayUint32)
+		if err := arrUint32.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayUint32Var(field.Addr().Interface().(*[]uint32), flgKey, []uint32(*arrUint32), "")
+	case float32:
+		val, err := parseFloat(value, 32, t)
+		if err != nil && value != "" {
+			return err
+		}
+		flagSet.Float32Var(field.Addr().Interface().(*float32), flgKey, float32(val), "")
+	case []float32:
+		arrFloat32 := new(arrayFloat32)
+		if err := arrFloat32.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayFloat32Var(field.Addr().Interface().(*[]float32), flgKey, []float32(*arrFloat32), "")
 	case string:
 		flagSet.StringVar(field.Addr().Interface().(*string), flgKey, value, "")
 	case []string:
-		flagSet.ArrayStringVar(field.Addr().Interface().(*[]string), flgKey, strings.Split(value, comma), "")
+		arrStr := new(arrayString)
+		if err := arrStr.Set(value); err != nil {
+			return err
+		}
+		flagSet.ArrayStringVar(field.Addr().Interface().(*[]string), flgKey, []string(*arrStr), "")
 	case bool:
 		val, err := strconv.ParseBool(value)
 		if err != nil {
@@ -231,11 +473,64 @@ func setValue(field reflect.Value, flagSet *FlagSet, flgKey, value string) error
 		}
 		flagSet.BoolVar(field.Addr().Interface().(*bool), flgKey, val, "")
 	default:
-		return errUnsupportedType(field.Kind().String())
+		// custom types implementing flag.Value or encoding.TextUnmarshaler
+		v := customValue(field)
+		if v == nil {
+			return errUnsupportedType(field.Kind().String())
+		}
+		return setFlagValue(v, field, flagSet, flgKey, value)
+	}
+	return nil
+}
+
+// addNames keeps the names of the field, different fields can not share the
+// flag, the env variable or the key of config files.
+func (s *loadState) addNames(f *fieldInfo, key string) error {
+	if other, ok := s.flags[f.Flag]; ok {
+		return errNameCollision("flag [--"+f.Flag+"]", other.Path)
+	}
+	if other, ok := s.envs[f.Env]; ok {
+		return errNameCollision("env ["+f.Env+"]", other.Path)
 	}
+	if other, ok := s.keys[key]; ok {
+		return errNameCollision("key ["+key+"]", other.Path)
+	}
+	s.flags[f.Flag], s.envs[f.Env], s.keys[key] = f, f, f
 	return nil
 }
 
+// joinPath adds the name of the field to the Go path.
+func joinPath(goPath, name string) string {
+	if goPath == "" {
+		return name
+	}
+	return goPath + "." + name
+}
+
+// isIgnored checks if the field is excluded from loading with the tag or
+// unexported (exported fields of embedded unexported structs are promoted
+// like in encoding/json).
+func isIgnored(field reflect.StructField) bool {
+	if field.Tag.Get(keyConfigTag) == "-" {
+		return true
+	}
+	return field.PkgPath != "" && !(field.Anonymous && field.Type.Kind() == reflect.Struct)
+}
+
+// isSquashed checks if the nested struct is flattened into the parent
+// namespace (embedded structs are flattened unless squash tag is false).
+func isSquashed(field reflect.StructField) (bool, error) {
+	tagValue, ok := field.Tag.Lookup(keySquashTag)
+	if !ok {
+		return field.Anonymous, nil
+	}
+	squash, err := strconv.ParseBool(tagValue)
+	if err != nil {
+		return false, errInvalidTag(keySquashTag, tagValue)
+	}
+	return squash, nil
+}
+
 // nestedPrefix concats prefix for generating default flag names and env variable names.
 func nestedPrefix(base, newPrefix string) string {
 	if base == "" {
@@ -245,25 +540,24 @@ func nestedPrefix(base, newPrefix string) string {
 }
 
 // envName gets environment variable name for passed field based on provided
-// struct tags or default rules (ENVPREFIX_STRUCTNAME_NESTEDSTRUCTNAME_VARNAME).
-func envName(field reflect.StructField, prefix string) string {
+// struct tags or the naming strategy (ENVPREFIX_STRUCTNAME_NESTEDSTRUCTNAME_VARNAME
+// by default).
+func envName(field reflect.StructField, envPrefix, prefix string, naming NamingStrategy) string {
 	tag := field.Tag.Get(keyEnvTag)
 	if tag != "" {
 		return tag
 	}
-	s := joinStrings("_", EnvPrefix, prefix, field.Name)
-	return strings.ToUpper(s)
+	return naming.name(envPrefix, prefix, field.Name)
 }
 
 // flagName gets flag name for passed field based on provided struct tags or
-// default rules (-structname-nestedstructname-varname).
-func flagName(field reflect.StructField, prefix string) string {
+// the naming strategy (-structname-nestedstructname-varname by default).
+func flagName(field reflect.StructField, prefix string, naming NamingStrategy) string {
 	tag := field.Tag.Get(keyFlagTag)
 	if tag != "" {
 		return tag
 	}
-	s := joinStrings("-", prefix, field.Name)
-	return strings.ToLower(s)
+	return naming.name(prefix, field.Name)
 }
 
 // joinStrings similar to strings.Join, but omits empty values, also replaces
@@ -109,14 +109,14 @@ func Test_FlagName(t *testing.T) {
 	Convey("Flag Name", t, func() {
 		for _, c := range cases {
 			Convey(c.title, func() {
-				So(flagName(c.in.field, c.in.prefix), ShouldEqual, c.out)
+				So(flagName(c.in.field, c.in.prefix, Lowercase), ShouldEqual, c.out)
 			})
 		}
 	})
 }
 
 func Test_EnvName(t *testing.T) {
-	EnvPrefix = "TEST"
+	const envPrefix = "TEST"
 
 	type In struct {
 		field  reflect.StructField
@@ -138,7 +138,7 @@ func Test_EnvName(t *testing.T) {
 				},
 				"Db",
 			},
-			EnvPrefix + "_DB_TEST",
+			envPrefix + "_DB_TEST",
 		},
 		{
 			"with provided tags",
@@ -156,7 +156,7 @@ func Test_EnvName(t *testing.T) {
 	Convey("Environment values", t, func() {
 		for _, c := range cases {
 			Convey(c.title, func() {
-				So(envName(c.in.field, c.in.prefix), ShouldEqual, c.out)
+				So(envName(c.in.field, envPrefix, c.in.prefix, Uppercase), ShouldEqual, c.out)
 			})
 		}
 	})
@@ -177,6 +177,11 @@ func Test_SetValue(t *testing.T) {
 		S    string
 		B    bool
 		F32  float32
+		C64  complex64
+		I8   int8
+		AI16 []int16
+		U16  uint16
+		AU8  []uint8
 		F64  float64
 		AF64 []float64
 		AS   []string
@@ -194,14 +199,61 @@ func Test_SetValue(t *testing.T) {
 	var reflectStruct = reflect.Indirect(reflect.ValueOf(new(testStruct)))
 	var cases = []testCase{
 		{
-			title: "unsupported float32 value",
+			title: "unsupported complex64 value",
+			in: in{
+				reflectStruct.FieldByName("C64"),
+				"flag-test",
+				"3.14159",
+			},
+			out: complex64(0),
+			err: errUnsupportedType(reflectStruct.FieldByName("C64").Kind().String()),
+		},
+		{
+			title: "float32 value",
 			in: in{
 				reflectStruct.FieldByName("F32"),
 				"flag-test",
 				"3.14159",
 			},
-			out: float32(0),
-			err: errUnsupportedType(reflectStruct.FieldByName("F32").Kind().String()),
+			out: float32(3.14159),
+		},
+		{
+			title: "int8 value",
+			in: in{
+				reflectStruct.FieldByName("I8"),
+				"flag-test",
+				"-128",
+			},
+			out: int8(-128),
+		},
+		{
+			title: "uint16 overflow",
+			in: in{
+				reflectStruct.FieldByName("U16"),
+				"flag-test",
+				"70000",
+			},
+			out: uint16(0),
+			err: errOutOfRange("70000", uint16(0), 16),
+		},
+		{
+			title: "[]int16 value",
+			in: in{
+				reflectStruct.FieldByName("AI16"),
+				"flag-test",
+				"-1,32767",
+			},
+			out: []int16{-1, 32767},
+		},
+		{
+			title: "[]uint8 overflow",
+			in: in{
+				reflectStruct.FieldByName("AU8"),
+				"flag-test",
+				"1,256",
+			},
+			out: []uint8(nil),
+			err: errOutOfRange("256", []uint8{}, 8),
 		},
 		{
 			title: "wrong time.Duration",
@@ -363,6 +415,15 @@ func Test_SetValue(t *testing.T) {
 			},
 			out: time.Duration(10800000000000),
 		},
+		{
+			title: "empty time.Duration value",
+			in: in{
+				reflectStruct.FieldByName("D"),
+				"flag-test",
+				"",
+			},
+			out: time.Duration(0),
+		},
 		{
 			title: "[]time.Duration value",
 			in: in{
@@ -489,6 +550,15 @@ func Test_SetValue(t *testing.T) {
 			},
 			out: []string{"foo", "bar"},
 		},
+		{
+			title: "empty array string value",
+			in: in{
+				reflectStruct.FieldByName("AS"),
+				"flag-test",
+				"",
+			},
+			out: []string{},
+		},
 	}
 	Convey("Setting values", t, func() {
 		for _, c := range cases {
@@ -518,40 +588,57 @@ func Test_Init(t *testing.T) {
 				error:  errInvalidReceiver,
 			},
 			{
-				title: "settability of unexported fields",
+				title: "unexported fields are skipped",
 				config: &struct {
-					value int
+					value complex64
 				}{},
 				prefix: emptyPrefix,
-				error:  errCantSet,
+				error:  nil,
 			},
 			{
-				title: "settability of nested unexported fields",
+				title: "nested unexported fields are skipped",
 				config: &struct {
 					Nested struct {
-						value int
+						value int `required:"true"`
 					}
 				}{},
 				prefix: emptyPrefix,
-				error:  errCantSet,
+				error:  nil,
+			},
+			{
+				title: "ignored fields are skipped",
+				config: &struct {
+					Value  complex64 `config:"-"`
+					Nested struct {
+						Value int `required:"true"`
+					} `config:"-"`
+				}{},
+				prefix: emptyPrefix,
+				error:  nil,
 			},
 			{
 				title: "unsupported type",
 				config: &struct {
-					Value float32 `default:"3.14159"`
+					Value complex64 `default:"3.14159"`
 				}{},
 				prefix: emptyPrefix,
-				error:  errUnsupportedType("float32"),
+				error: Errors{&UnsupportedTypeError{
+					Field: Field{Path: "Value", Flag: "value", Env: "VALUE", Source: SourceDefault},
+					Type:  "complex64",
+				}},
 			},
 			{
 				title: "nested struct unsupported type",
 				config: &struct {
 					Struct struct {
-						Value float32 `default:"3.14159"`
+						Value complex64 `default:"3.14159"`
 					}
 				}{},
 				prefix: emptyPrefix,
-				error:  errUnsupportedType("float32"),
+				error: Errors{&UnsupportedTypeError{
+					Field: Field{Path: "Struct.Value", Flag: "struct-value", Env: "STRUCT_VALUE", Source: SourceDefault},
+					Type:  "complex64",
+				}},
 			},
 			{
 				title: "check required value",
@@ -559,7 +646,26 @@ func Test_Init(t *testing.T) {
 					Value int `required:"true"`
 				}{},
 				prefix: emptyPrefix,
-				error:  errMissingRequired("value"),
+				error:  Errors{errMissingRequired(Field{Path: "Value", Flag: "value", Env: "VALUE"})},
+			},
+			{
+				title: "all the problems are reported at once",
+				config: &struct {
+					First  int       `required:"true"`
+					Second complex64 `default:"3.14159"`
+					Nested struct {
+						Third int `required:"true"`
+					}
+				}{},
+				prefix: emptyPrefix,
+				error: Errors{
+					&UnsupportedTypeError{
+						Field: Field{Path: "Second", Flag: "second", Env: "SECOND", Source: SourceDefault},
+						Type:  "complex64",
+					},
+					errMissingRequired(Field{Path: "First", Flag: "first", Env: "FIRST"}),
+					errMissingRequired(Field{Path: "Nested.Third", Flag: "nested-third", Env: "NESTED_THIRD"}),
+				},
 			},
 			{
 				title: "set struct default value",
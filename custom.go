@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isCustom checks if the pointer to the type implements flag.Value or
+// encoding.TextUnmarshaler.
+func isCustom(typ reflect.Type) bool {
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(flagValueType) || ptr.Implements(textUnmarshalerType)
+}
+
+// customValue returns flag.Value of the field of custom type (or slice of
+// custom type), nil if the type is not custom.
+func customValue(field reflect.Value) flag.Value {
+	switch ptr := field.Addr(); {
+	case ptr.Type().Implements(flagValueType):
+		return ptr.Interface().(flag.Value)
+	case ptr.Type().Implements(textUnmarshalerType):
+		return &funcValue{ptr: ptr, set: setCustom}
+	case field.Kind() == reflect.Slice && isCustom(field.Type().Elem()):
+		return &sliceValue{ptr: ptr, set: setCustom}
+	default:
+		return nil
+	}
+}
+
+// setCustom parses the value with flag.Value or encoding.TextUnmarshaler
+// implemented by the pointer.
+func setCustom(ptr reflect.Value, value string) error {
+	if v, ok := ptr.Interface().(flag.Value); ok {
+		return v.Set(value)
+	}
+	return ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+}
+
+// setFlagValue assigns the value (if provided) with flag.Value and defines the
+// flag.
+func setFlagValue(v flag.Value, field reflect.Value, flagSet *FlagSet, flgKey, value string) error {
+	if value != "" {
+		if err := v.Set(value); err != nil {
+			if e, ok := err.(*ParseError); ok {
+				return e
+			}
+			return &ParseError{Value: value, Type: field.Type().String(), Err: err}
+		}
+	}
+	flagSet.Var(v, flgKey, "")
+	return nil
+}
+
+// funcValue is a flag.Value that parses the value with the func.
+type funcValue struct {
+	ptr reflect.Value
+	set func(reflect.Value, string) error
+}
+
+// Set parses the value.
+func (v *funcValue) Set(value string) error {
+	return v.set(v.ptr, value)
+}
+
+// String formats the value.
+func (v *funcValue) String() string {
+	if !v.ptr.IsValid() {
+		return ""
+	}
+	return formatValue(v.ptr.Elem())
+}
+
+// sliceValue is a flag.Value of a slice, comma-separated items are parsed with
+// the func.
+type sliceValue struct {
+	ptr reflect.Value
+	set func(reflect.Value, string) error
+}
+
+// Set parses the items of the slice.
+func (v *sliceValue) Set(value string) error {
+	typ := v.ptr.Type().Elem()
+	slice := reflect.MakeSlice(typ, 0, 0)
+	if value != "" {
+		for _, item := range strings.Split(value, comma) {
+			elem := reflect.New(typ.Elem())
+			if err := v.set(elem, item); err != nil {
+				return &ParseError{Value: item, Type: typ.Elem().String(), Err: err}
+			}
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+	v.ptr.Elem().Set(slice)
+	return nil
+}
+
+// String returns comma-separated items.
+func (v *sliceValue) String() string {
+	if !v.ptr.IsValid() {
+		return ""
+	}
+	return formatValue(v.ptr.Elem())
+}
+
+// formatText formats the value with encoding.TextMarshaler or fmt.Stringer
+// (implemented by the value or the pointer to it).
+func formatText(v reflect.Value) string {
+	value := v.Interface()
+	if v.CanAddr() {
+		value = v.Addr().Interface()
+	}
+	switch t := value.(type) {
+	case encoding.TextMarshaler:
+		if text, err := t.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return t.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testLevel is an enum implementing encoding.TextUnmarshaler.
+type testLevel int
+
+const (
+	levelDebug testLevel = iota
+	levelInfo
+	levelError
+)
+
+var levelNames = []string{"debug", "info", "error"}
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	for i, name := range levelNames {
+		if strings.EqualFold(string(text), name) {
+			*l = testLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte(levelNames[l]), nil
+}
+
+// testList implements flag.Value (items are separated with semicolons).
+type testList []string
+
+func (l *testList) Set(value string) error {
+	*l = strings.Split(value, ";")
+	return nil
+}
+
+func (l *testList) String() string {
+	return strings.Join(*l, ";")
+}
+
+func Test_Loader_CustomTypes(t *testing.T) {
+	type testConfig struct {
+		Level   testLevel `default:"info"`
+		Levels  []testLevel
+		IP      net.IP `default:"127.0.0.1"`
+		IPs     []net.IP
+		Big     big.Int
+		Since   time.Time
+		List    testList
+		Nested  struct{ Level testLevel }
+		Missing testLevel
+	}
+	Convey("Custom types", t, func() {
+		Convey("values from all the layers", func() {
+			path := writeFile(t, "app.json", `{"big": 12345678901234567890123, "since": "2020-01-02T03:04:05Z", "nested": {"level": "error"}}`)
+			conf := new(testConfig)
+			l := NewLoader(
+				WithArgs([]string{"-levels", "debug,error", "-list", "a;b"}),
+				WithLookupEnv(mapEnv(map[string]string{"IPS": "10.0.0.1,10.0.0.2"})),
+				WithFile(path),
+			)
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Level, ShouldEqual, levelInfo)
+			So(conf.Levels, ShouldResemble, []testLevel{levelDebug, levelError})
+			So(conf.IP.String(), ShouldEqual, "127.0.0.1")
+			So(conf.IPs, ShouldHaveLength, 2)
+			So(conf.IPs[1].String(), ShouldEqual, "10.0.0.2")
+			So(conf.Big.String(), ShouldEqual, "12345678901234567890123")
+			So(conf.Since.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), ShouldBeTrue)
+			So(conf.List, ShouldResemble, testList{"a", "b"})
+			So(conf.Nested.Level, ShouldEqual, levelError)
+			So(conf.Missing, ShouldEqual, levelDebug)
+			Convey("dump", func() {
+				var buf bytes.Buffer
+				So(report.Dump(&buf, FormatJSON), ShouldBeNil)
+				So(buf.String(), ShouldContainSubstring, `"value": "12345678901234567890123"`)
+				So(buf.String(), ShouldContainSubstring, `"value": [
+      "10.0.0.1",
+      "10.0.0.2"
+    ]`)
+			})
+		})
+		Convey("values set before loading", func() {
+			conf := &testConfig{Levels: []testLevel{levelError}}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldBeNil)
+			So(conf.Levels, ShouldResemble, []testLevel{levelError})
+		})
+		Convey("invalid values", func() {
+			l := NewLoader(
+				WithArgs([]string{"-levels", "info,fatal"}),
+				WithLookupEnv(mapEnv(map[string]string{"LEVEL": "trace"})),
+			)
+			err := l.Load(new(testConfig))
+			So(err, ShouldResemble, Errors{
+				&ParseError{
+					Field: Field{Path: "Level", Flag: "level", Env: "LEVEL", Source: SourceEnv},
+					Value: "trace",
+					Type:  "config.testLevel",
+					Err:   fmt.Errorf("unknown level %q", "trace"),
+				},
+				&ParseError{
+					Field: Field{Path: "Levels", Flag: "levels", Env: "LEVELS", Source: SourceFlag},
+					Value: "fatal",
+					Type:  "config.testLevel",
+					Err:   fmt.Errorf("unknown level %q", "fatal"),
+				},
+			})
+		})
+	})
+}
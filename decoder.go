@@ -0,0 +1,83 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// DecoderFunc parses the raw value (provided by any source) into the value of
+// the type the decoder is registered for.
+type DecoderFunc func(string) (interface{}, error)
+
+// RegisterDecoder registers the decoder of the type (e.g. the third-party one
+// that can not implement encoding.TextUnmarshaler). The decoder is used for
+// the fields of the type and the slices of it (items are comma-separated) by
+// all the layers, it takes precedence over built-in types and interfaces.
+func (l *Loader) RegisterDecoder(typ reflect.Type, decode DecoderFunc) {
+	if l.decoders == nil {
+		l.decoders = make(map[reflect.Type]DecoderFunc)
+	}
+	l.decoders[typ] = decode
+}
+
+// WithDecoder registers the decoder of the type (see RegisterDecoder).
+func WithDecoder(typ reflect.Type, decode DecoderFunc) Option {
+	return func(l *Loader) { l.RegisterDecoder(typ, decode) }
+}
+
+// textType returns the type that is parsed from text by the decoder or the
+// interface (the type of the field or its items), nil for built-in types.
+func (s *loadState) textType(typ reflect.Type) reflect.Type {
+	if _, ok := s.decoders[typ]; ok || isCustom(typ) {
+		return typ
+	}
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+		if _, ok := s.decoders[typ.Elem()]; ok || isCustom(typ.Elem()) {
+			return typ.Elem()
+		}
+	}
+	return nil
+}
+
+// setValue assigns the value to the field with the registered decoder (falls
+// back to maps, built-in types and interfaces) and defines the flag.
+func (s *loadState) setValue(field reflect.Value, flagSet *FlagSet, flgKey, value string) error {
+	typ := field.Type()
+	if decode, ok := s.decoders[typ]; ok {
+		return setFlagValue(&funcValue{ptr: field.Addr(), set: decodeWith(decode)}, field, flagSet, flgKey, value)
+	}
+	if typ.Kind() == reflect.Slice {
+		if decode, ok := s.decoders[typ.Elem()]; ok {
+			return setFlagValue(&sliceValue{ptr: field.Addr(), set: decodeWith(decode)}, field, flagSet, flgKey, value)
+		}
+	}
+	if typ.Kind() == reflect.Map && !isCustom(typ) {
+		return s.setMap(field, flagSet, flgKey, value)
+	}
+	return setValue(field, flagSet, flgKey, value)
+}
+
+// parseValue parses the value the same way as the value of the field of the
+// type.
+func (s *loadState) parseValue(typ reflect.Type, flgKey, value string) (reflect.Value, error) {
+	elem := reflect.New(typ).Elem()
+	err := s.setValue(elem, NewFlagSet(flgKey, flag.ContinueOnError), flgKey, value)
+	return elem, err
+}
+
+// decodeWith returns the func that assigns the decoded value to the pointer.
+func decodeWith(decode DecoderFunc) func(reflect.Value, string) error {
+	return func(ptr reflect.Value, value string) error {
+		decoded, err := decode(value)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(decoded)
+		if !rv.IsValid() || !rv.Type().AssignableTo(ptr.Type().Elem()) {
+			return fmt.Errorf("decoder returned [%T] instead of [%s]", decoded, ptr.Type().Elem())
+		}
+		ptr.Elem().Set(rv)
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Loader_RegisterDecoder(t *testing.T) {
+	decodeURL := func(value string) (interface{}, error) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	}
+	decodeHex := func(value string) (interface{}, error) {
+		i, err := strconv.ParseInt(value, 0, 64)
+		return int(i), err
+	}
+	type testConfig struct {
+		Endpoint url.URL `default:"http://localhost:8080/api"`
+		Mirrors  []url.URL
+		Nested   struct {
+			Proxy url.URL
+		}
+		Mask int `default:"0xff"`
+	}
+	Convey("Registered decoders", t, func() {
+		Convey("values from all the layers", func() {
+			path := writeFile(t, "app.yaml", "nested:\n  proxy: http://proxy.local:3128\n")
+			conf := new(testConfig)
+			l := NewLoader(
+				WithArgs([]string{"-mirrors", "http://a.local,http://b.local"}),
+				WithLookupEnv(mapEnv(nil)),
+				WithFile(path),
+				WithDecoder(reflect.TypeOf(url.URL{}), decodeURL),
+			)
+			l.RegisterDecoder(reflect.TypeOf(0), decodeHex)
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Endpoint.Host, ShouldEqual, "localhost:8080")
+			So(conf.Mirrors, ShouldHaveLength, 2)
+			So(conf.Mirrors[1].Host, ShouldEqual, "b.local")
+			So(conf.Nested.Proxy.Port(), ShouldEqual, "3128")
+			So(conf.Mask, ShouldEqual, 255)
+			Convey("dump", func() {
+				var buf bytes.Buffer
+				So(report.Dump(&buf, FormatTable), ShouldBeNil)
+				So(buf.String(), ShouldContainSubstring, "http://a.local,http://b.local")
+				So(buf.String(), ShouldContainSubstring, "http://proxy.local:3128")
+			})
+		})
+		Convey("decoder errors", func() {
+			l := NewLoader(
+				WithArgs(nil),
+				WithLookupEnv(mapEnv(map[string]string{"MIRRORS": "http://a.local,%zz", "MASK": "0xzz"})),
+				WithDecoder(reflect.TypeOf(url.URL{}), decodeURL),
+				WithDecoder(reflect.TypeOf(0), func(string) (interface{}, error) { return "text", nil }),
+			)
+			err := l.Load(new(testConfig))
+			_, urlErr := url.Parse("%zz")
+			So(err, ShouldResemble, Errors{
+				&ParseError{
+					Field: Field{Path: "Mirrors", Flag: "mirrors", Env: "MIRRORS", Source: SourceEnv},
+					Value: "%zz",
+					Type:  "url.URL",
+					Err:   urlErr,
+				},
+				&ParseError{
+					Field: Field{Path: "Mask", Flag: "mask", Env: "MASK", Source: SourceEnv},
+					Value: "0xzz",
+					Type:  "int",
+					Err:   fmt.Errorf("decoder returned [string] instead of [int]"),
+				},
+			})
+		})
+	})
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// exportPrefix is an optional prefix of the dotenv entry
+const exportPrefix = "export"
+
+// loadDotEnv reads the dotenv files (missing files are skipped), values from
+// the following files override the previous ones. The ${VAR} references are
+// resolved with entries defined above and then with the lookup func. Returns
+// the entries and the files they come from.
+func loadDotEnv(files []string, lookup func(string) (string, bool)) (env, origins map[string]string, err error) {
+	env, origins = make(map[string]string), make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return env, origins, err
+		}
+		p := &dotEnvParser{file: file, data: string(data), line: 1, env: env, origins: origins, lookup: lookup}
+		if err := p.parse(); err != nil {
+			return env, origins, err
+		}
+	}
+	return env, origins, nil
+}
+
+// dotEnvParser parses the contents of a single dotenv file.
+type dotEnvParser struct {
+	// file is a path to the file (used in error messages)
+	file string
+	// data is the contents of the file
+	data string
+	// pos is the current position and line is the current line number
+	pos, line int
+	// env contains parsed entries and origins contains the files they come from
+	env, origins map[string]string
+	// lookup retrieves the values of undefined references
+	lookup func(string) (string, bool)
+}
+
+// parse reads the entries one by one until the end of file.
+func (p *dotEnvParser) parse() error {
+	for {
+		p.skip(" \t\r\n")
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		if err := p.entry(); err != nil {
+			return err
+		}
+	}
+}
+
+// entry parses a single KEY=value entry (with optional "export" prefix).
+func (p *dotEnvParser) entry() error {
+	key := p.key()
+	if key == exportPrefix && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skip(" \t")
+		key = p.key()
+	}
+	if key == "" {
+		return errDotEnvSyntax(p.file, p.line, "invalid variable name")
+	}
+	p.skip(" \t")
+	if p.eof() || p.peek() != '=' {
+		return errDotEnvSyntax(p.file, p.line, "missing \"=\" after ["+key+"]")
+	}
+	p.pos++
+	p.skip(" \t")
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	p.env[key], p.origins[key] = value, p.file
+	return nil
+}
+
+// key reads a variable name.
+func (p *dotEnvParser) key() string {
+	start := p.pos
+	for !p.eof() && isNameChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+// value reads quoted or unquoted value and the rest of the line.
+func (p *dotEnvParser) value() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+	switch quote := p.peek(); quote {
+	case '\'', '"':
+		line := p.line
+		p.pos++
+		var value strings.Builder
+		for {
+			if p.eof() {
+				return "", errDotEnvSyntax(p.file, line, "unterminated quoted value")
+			}
+			ch := p.next()
+			switch {
+			case ch == quote:
+				// only whitespaces and comments are allowed after the closing quote
+				p.skip(" \t\r")
+				if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+					return "", errDotEnvSyntax(p.file, p.line, "unexpected characters after quoted value")
+				}
+				p.skipLine()
+				return value.String(), nil
+			case quote == '"' && ch == '\\' && !p.eof():
+				value.WriteString(unescape(p.next()))
+			case quote == '"' && ch == '$':
+				ref, err := p.reference()
+				if err != nil {
+					return "", err
+				}
+				value.WriteString(ref)
+			default:
+				value.WriteByte(ch)
+			}
+		}
+	default:
+		var value strings.Builder
+		for !p.eof() && p.peek() != '\n' {
+			ch := p.next()
+			switch {
+			case ch == '#' && (value.Len() == 0 || strings.ContainsAny(p.data[p.pos-2:p.pos-1], " \t")):
+				// inline comment
+				p.skipLine()
+				return strings.TrimSpace(value.String()), nil
+			case ch == '$':
+				ref, err := p.reference()
+				if err != nil {
+					return "", err
+				}
+				value.WriteString(ref)
+			default:
+				value.WriteByte(ch)
+			}
+		}
+		return strings.TrimSpace(value.String()), nil
+	}
+}
+
+// reference resolves ${VAR} reference ("$" has been already read), a single
+// "$" that is not followed by "{" is kept as is.
+func (p *dotEnvParser) reference() (string, error) {
+	if p.eof() || p.peek() != '{' {
+		return "$", nil
+	}
+	end := strings.IndexByte(p.data[p.pos:], '}')
+	if end < 0 {
+		return "", errDotEnvSyntax(p.file, p.line, "unterminated variable reference")
+	}
+	name := p.data[p.pos+1 : p.pos+end]
+	p.pos += end + 1
+	if value, ok := p.env[name]; ok {
+		return value, nil
+	}
+	value, _ := p.lookup(name)
+	return value, nil
+}
+
+// eof checks if the end of file has been reached.
+func (p *dotEnvParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// peek returns the current character.
+func (p *dotEnvParser) peek() byte {
+	return p.data[p.pos]
+}
+
+// next returns the current character and moves to the next one.
+func (p *dotEnvParser) next() byte {
+	ch := p.data[p.pos]
+	if ch == '\n' {
+		p.line++
+	}
+	p.pos++
+	return ch
+}
+
+// skip skips provided characters.
+func (p *dotEnvParser) skip(chars string) {
+	for !p.eof() && strings.IndexByte(chars, p.peek()) >= 0 {
+		p.next()
+	}
+}
+
+// skipLine skips everything till the end of the line.
+func (p *dotEnvParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+// isNameChar checks if the character can be used in a variable name.
+func isNameChar(ch byte, first bool) bool {
+	switch {
+	case ch == '_', ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z':
+		return true
+	case ch >= '0' && ch <= '9', ch == '.':
+		return !first
+	}
+	return false
+}
+
+// unescape returns the character represented by the escape sequence.
+func unescape(ch byte) string {
+	switch ch {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	default:
+		return string(ch)
+	}
+}
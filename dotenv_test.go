@@ -0,0 +1,114 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_loadDotEnv(t *testing.T) {
+	Convey("dotenv files", t, func() {
+		lookup := mapEnv(map[string]string{"HOME": "/home/user", "EMPTY": ""})
+
+		Convey("parse entries", func() {
+			path := writeFile(t, ".env", `
+# comment
+PLAIN=value
+SPACES = spaced value
+export EXPORTED=exported
+INLINE=value # inline comment
+HASH=value#not-a-comment
+EMPTY_VALUE=
+SINGLE='single ${HOME} \n # not a comment'
+DOUBLE="double\t\"quoted\"" # comment
+MULTILINE="first line
+second line"
+ESCAPED="\${HOME}"
+REFERENCE=${HOME}/app
+NESTED="${REFERENCE}/data"
+UNDEFINED=${UNDEFINED}
+DOLLAR=$HOME
+dotted.name=dotted
+`)
+			env, origins, err := loadDotEnv([]string{path}, lookup)
+			So(err, ShouldBeNil)
+			So(env, ShouldResemble, map[string]string{
+				"PLAIN":       "value",
+				"SPACES":      "spaced value",
+				"EXPORTED":    "exported",
+				"INLINE":      "value",
+				"HASH":        "value#not-a-comment",
+				"EMPTY_VALUE": "",
+				"SINGLE":      `single ${HOME} \n # not a comment`,
+				"DOUBLE":      "double\t\"quoted\"",
+				"MULTILINE":   "first line\nsecond line",
+				"ESCAPED":     "${HOME}",
+				"REFERENCE":   "/home/user/app",
+				"NESTED":      "/home/user/app/data",
+				"UNDEFINED":   "",
+				"DOLLAR":      "$HOME",
+				"dotted.name": "dotted",
+			})
+			So(origins, ShouldContainKey, "PLAIN")
+			So(origins["PLAIN"], ShouldEqual, path)
+		})
+
+		Convey("ordered override", func() {
+			first := writeFile(t, ".env", "A=first\nB=first\n")
+			second := writeFile(t, ".env.local", "B=second\nC=${A}-${B}\n")
+			missing := filepath.Join(t.TempDir(), ".env.missing")
+			env, origins, err := loadDotEnv([]string{first, missing, second}, lookup)
+			So(err, ShouldBeNil)
+			So(env, ShouldResemble, map[string]string{"A": "first", "B": "second", "C": "first-second"})
+			So(origins, ShouldResemble, map[string]string{"A": first, "B": second, "C": second})
+		})
+
+		Convey("syntax errors", func() {
+			type testCase struct {
+				title    string
+				contents string
+				line     int
+				msg      string
+			}
+			var cases = []testCase{
+				{"invalid variable name", "A=1\n1A=2\n", 2, "invalid variable name"},
+				{"missing equal sign", "\n\nNAME value\n", 3, `missing "=" after [NAME]`},
+				{"unterminated quoted value", "A=1\nB=\"value\n\n", 2, "unterminated quoted value"},
+				{"characters after quoted value", "A='value' tail\n", 1, "unexpected characters after quoted value"},
+				{"unterminated reference", "A=${HOME\n", 1, "unterminated variable reference"},
+			}
+			for _, c := range cases {
+				Convey(c.title, func() {
+					path := writeFile(t, ".env", c.contents)
+					_, _, err := loadDotEnv([]string{path}, lookup)
+					So(err, ShouldResemble, errDotEnvSyntax(path, c.line, c.msg))
+				})
+			}
+		})
+	})
+}
+
+func Test_Loader_DotEnv(t *testing.T) {
+	Convey("dotenv files layer", t, func() {
+		type testConfig struct {
+			Name   string `default:"default"`
+			Port   int    `default:"80"`
+			Nested struct {
+				Value string
+			}
+		}
+		path := writeFile(t, ".env", "APP_NAME=dotenv\nAPP_PORT=8080\nAPP_NESTED_VALUE=nested\n")
+		conf := new(testConfig)
+		l := NewLoader(
+			WithEnvPrefix("APP"),
+			WithArgs(nil),
+			WithDotEnvFiles(path),
+			WithLookupEnv(mapEnv(map[string]string{"APP_PORT": "9090"})),
+		)
+		So(l.Load(conf), ShouldBeNil)
+		So(conf.Name, ShouldEqual, "dotenv")
+		So(conf.Port, ShouldEqual, 9090)
+		So(conf.Nested.Value, ShouldEqual, "nested")
+	})
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format of the config dump.
+type Format int
+
+// Supported dump formats.
+const (
+	FormatTable Format = iota
+	FormatJSON
+	FormatYAML
+)
+
+// dumpEntry is a single config field in the dump.
+type dumpEntry struct {
+	Path   string      `json:"path" yaml:"path"`
+	Flag   string      `json:"flag" yaml:"flag"`
+	Env    string      `json:"env" yaml:"env"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source Source      `json:"source,omitempty" yaml:"source,omitempty"`
+	File   string      `json:"file,omitempty" yaml:"file,omitempty"`
+	Key    string      `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// Dump renders the effective config (the values of the loaded struct) with
+// field paths, flag and env variable names and the sources of the values. The
+// values of the fields tagged as secret are masked.
+func (r *Report) Dump(w io.Writer, format Format) error {
+	entries := make([]dumpEntry, len(r.origins))
+	for i, origin := range r.origins {
+		entries[i] = dumpEntry{
+			Path:   origin.Path,
+			Flag:   origin.Flag,
+			Env:    origin.Env,
+			Value:  dumpValue(origin.current, origin.text),
+			Source: origin.Source,
+			File:   origin.File,
+			Key:    origin.Key,
+		}
+		if origin.Secret {
+			entries[i].Value = secretMask
+		}
+	}
+	switch format {
+	case FormatTable:
+		return dumpTable(w, r.origins, entries)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(entries); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unknown dump format [%d]", format)
+	}
+}
+
+// dumpValue converts durations (like "1m30s") and values parsed from text
+// (custom types) to strings.
+func dumpValue(rv reflect.Value, text reflect.Type) interface{} {
+	if rv.Kind() == reflect.Ptr && rv.Type() != text {
+		// pointer leaf
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type() != text:
+		items := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			items[key.String()] = dumpValue(rv.MapIndex(key), text)
+		}
+		return items
+	case text == nil:
+	case rv.Type() == text:
+		return formatValue(rv)
+	case rv.Type().Elem() == text:
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = formatValue(rv.Index(i))
+		}
+		return items
+	}
+	switch v := rv.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []time.Duration:
+		items := make([]string, len(v))
+		for i, d := range v {
+			items[i] = d.String()
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// dumpTable renders the dump as a table (slices are rendered as comma-separated
+// lists, the same way they are provided with env variables and flags).
+func dumpTable(w io.Writer, origins []Origin, entries []dumpEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tFLAG\tENV\tVALUE\tSOURCE")
+	for i, entry := range entries {
+		value := entry.Value
+		if value == nil {
+			// nil pointer
+			value = "-"
+		}
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map {
+			value = formatValue(rv)
+		}
+		source := origins[i].origin()
+		if source == "" {
+			source = "-"
+		}
+		fmt.Fprintf(tw, "%s\t--%s\t%s\t%v\t%s\n", entry.Path, entry.Flag, entry.Env, value, source)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Report_Dump(t *testing.T) {
+	type testConfig struct {
+		Name     string          `default:"app"`
+		Timeouts []time.Duration `default:"1s,1m"`
+		Database struct {
+			Port     int    `default:"5432"`
+			Password string `secret:"true"`
+		}
+	}
+	Convey("Dump", t, func() {
+		l := NewLoader(
+			WithEnvPrefix("APP"),
+			WithArgs([]string{"--database-port", "5433"}),
+			WithLookupEnv(mapEnv(map[string]string{"APP_DATABASE_PASSWORD": "qwerty"})),
+		)
+		report, err := l.LoadReport(new(testConfig))
+		So(err, ShouldBeNil)
+		var buf bytes.Buffer
+
+		Convey("table", func() {
+			So(report.Dump(&buf, FormatTable), ShouldBeNil)
+			So(buf.String(), ShouldEqual, ""+
+				"FIELD              FLAG                 ENV                    VALUE    SOURCE\n"+
+				"Name               --name               APP_NAME               app      default value\n"+
+				"Timeouts           --timeouts           APP_TIMEOUTS           1s,1m0s  default value\n"+
+				"Database.Port      --database-port      APP_DATABASE_PORT      5433     flag --database-port\n"+
+				"Database.Password  --database-password  APP_DATABASE_PASSWORD  ******   env APP_DATABASE_PASSWORD\n")
+		})
+
+		Convey("JSON", func() {
+			So(report.Dump(&buf, FormatJSON), ShouldBeNil)
+			So(buf.String(), ShouldEqual, `[
+  {
+    "path": "Name",
+    "flag": "name",
+    "env": "APP_NAME",
+    "value": "app",
+    "source": "default"
+  },
+  {
+    "path": "Timeouts",
+    "flag": "timeouts",
+    "env": "APP_TIMEOUTS",
+    "value": [
+      "1s",
+      "1m0s"
+    ],
+    "source": "default"
+  },
+  {
+    "path": "Database.Port",
+    "flag": "database-port",
+    "env": "APP_DATABASE_PORT",
+    "value": 5433,
+    "source": "flag"
+  },
+  {
+    "path": "Database.Password",
+    "flag": "database-password",
+    "env": "APP_DATABASE_PASSWORD",
+    "value": "******",
+    "source": "env"
+  }
+]
+`)
+		})
+
+		Convey("YAML", func() {
+			So(report.Dump(&buf, FormatYAML), ShouldBeNil)
+			So(buf.String(), ShouldEqual, `- path: Name
+  flag: name
+  env: APP_NAME
+  value: app
+  source: default
+- path: Timeouts
+  flag: timeouts
+  env: APP_TIMEOUTS
+  value:
+    - 1s
+    - 1m0s
+  source: default
+- path: Database.Port
+  flag: database-port
+  env: APP_DATABASE_PORT
+  value: 5433
+  source: flag
+- path: Database.Password
+  flag: database-password
+  env: APP_DATABASE_PASSWORD
+  value: '******'
+  source: env
+`)
+		})
+
+		Convey("unknown format", func() {
+			So(report.Dump(&buf, Format(42)), ShouldNotBeNil)
+		})
+
+		Convey("secrets are masked in the report", func() {
+			origin, _ := report.Provenance("Database.Password")
+			So(origin.Secret, ShouldBeTrue)
+			So(origin.String(), ShouldEqual, `Database.Password = "******" (env APP_DATABASE_PASSWORD)`)
+		})
+	})
+}
+
+func Test_SecretParseErrors(t *testing.T) {
+	type testConfig struct {
+		Pin int `secret:"true"`
+	}
+	Convey("Raw values of secret fields are masked in parse errors", t, func() {
+		Convey("env", func() {
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(map[string]string{"PIN": "12ab"}))).Load(new(testConfig))
+			So(err, ShouldResemble, Errors{&ParseError{
+				Field: Field{Path: "Pin", Flag: "pin", Env: "PIN", Source: SourceEnv},
+				Value: secretMask,
+				Type:  "int",
+			}})
+			So(err.Error(), ShouldEqual, "field [Pin]: cannot use [******] as type [int] (env PIN)")
+		})
+		Convey("flag", func() {
+			err := NewLoader(WithArgs([]string{"--pin", "12ab"}), WithLookupEnv(mapEnv(nil)), WithOutput(new(bytes.Buffer))).Load(new(testConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldNotContainSubstring, "12ab")
+			So(err.Error(), ShouldStartWith, "field [Pin]: cannot use [******] as type [int]")
+		})
+		Convey("file", func() {
+			path := writeFile(t, "config.json", `{"pin": "12ab"}`)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path)).Load(new(testConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldNotContainSubstring, "12ab")
+		})
+		Convey("causes of the errors", func() {
+			type causes struct {
+				IP     net.IP            `secret:"true"`
+				Tokens map[string]string `secret:"true"`
+			}
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(map[string]string{
+				"IP":     "hunter2",
+				"TOKENS": "hunter2",
+			}))).Load(new(causes))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldNotContainSubstring, "hunter2")
+			So(err.Error(), ShouldContainSubstring, "invalid net.IP value")
+		})
+		Convey("output of the flags", func() {
+			out := new(bytes.Buffer)
+			err := NewLoader(WithArgs([]string{"--pin", "hunter2"}), WithLookupEnv(mapEnv(nil)), WithOutput(out)).Load(new(testConfig))
+			So(err, ShouldNotBeNil)
+			So(out.String(), ShouldNotContainSubstring, "hunter2")
+			So(out.String(), ShouldStartWith, "field [Pin]: cannot use [******] as type [int]")
+			So(out.String(), ShouldContainSubstring, "Usage of ")
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type EmbedHTTP struct {
+	Host string `default:"localhost"`
+	Port int    `default:"80"`
+}
+
+// embedCalls counts the calls of the promoted hook
+var embedCalls int
+
+func (h *EmbedHTTP) Validate() error {
+	embedCalls++
+	return nil
+}
+
+type EmbedLimits struct {
+	Rate int
+}
+
+func Test_Loader_Embedded(t *testing.T) {
+	type testConfig struct {
+		EmbedHTTP
+		Limits EmbedLimits `squash:"true"`
+		Admin  struct {
+			EmbedHTTP `squash:"false"`
+		}
+	}
+	Convey("Embedded structs", t, func() {
+		embedCalls = 0
+		Convey("are flattened into the parent namespace", func() {
+			conf := new(testConfig)
+			report, err := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs([]string{"--port", "8080", "--rate", "10", "--admin-embedhttp-port", "9090"}),
+				WithLookupEnv(mapEnv(map[string]string{"APP_HOST": "example.com"})),
+			).LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Host, ShouldEqual, "example.com")
+			So(conf.Port, ShouldEqual, 8080)
+			So(conf.Limits.Rate, ShouldEqual, 10)
+			So(conf.Admin.Port, ShouldEqual, 9090)
+			origin, ok := report.Provenance("EmbedHTTP.Host")
+			So(ok, ShouldBeTrue)
+			So(origin.Env, ShouldEqual, "APP_HOST")
+			So(origin.Source, ShouldEqual, SourceEnv)
+			origin, ok = report.Provenance("Admin.EmbedHTTP.Port")
+			So(ok, ShouldBeTrue)
+			So(origin.Flag, ShouldEqual, "admin-embedhttp-port")
+		})
+		Convey("file keys are flattened as well", func() {
+			path := filepath.Join(t.TempDir(), "config.json")
+			So(os.WriteFile(path, []byte(`{"port": 8081, "rate": 5}`), 0o600), ShouldBeNil)
+			conf := new(testConfig)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path)).Load(conf)
+			So(err, ShouldBeNil)
+			So(conf.Port, ShouldEqual, 8081)
+			So(conf.Limits.Rate, ShouldEqual, 5)
+		})
+		Convey("promoted hooks are called once", func() {
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(new(testConfig))
+			So(err, ShouldBeNil)
+			// the root (promoted) and Admin.EmbedHTTP (promoted to Admin)
+			So(embedCalls, ShouldEqual, 2)
+		})
+		Convey("name collisions are reported", func() {
+			type collision struct {
+				EmbedHTTP
+				Port int
+			}
+			conf := new(collision)
+			err := NewLoader(WithArgs([]string{"--port", "1"}), WithLookupEnv(mapEnv(nil))).Load(conf)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "field [Port]: flag [--port] collides with field [EmbedHTTP.Port]")
+			var fieldErr *FieldError
+			So(errors.As(err, &fieldErr), ShouldBeTrue)
+			So(fieldErr.Path, ShouldEqual, "Port")
+		})
+		Convey("invalid squash tag", func() {
+			type invalid struct {
+				EmbedHTTP `squash:"maybe"`
+			}
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(new(invalid))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `field [EmbedHTTP]: invalid [squash:"maybe"] tag`)
+		})
+	})
+}
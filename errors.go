@@ -0,0 +1,261 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors contains all the problems found while loading the config (so they
+// can be fixed at once instead of one by one).
+type Errors []error
+
+// Error formats the list of errors (one per line).
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d config errors:", len(e))
+	for _, err := range e {
+		b.WriteString("\n\t- ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Is reports whether any error in the list matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the list that matches target, and if so, sets
+// target to that error value and returns true.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Source is a layer that supplies config values.
+type Source string
+
+// Sources of config values (from low to high priority).
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceDotEnv  Source = "dotenv"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Field identifies the config field and the source of its value.
+type Field struct {
+	// Path is a Go path of the field (e.g. "Database.Port")
+	Path string
+	// Flag is a name of the flag (without dashes)
+	Flag string
+	// Env is a name of the environment variable
+	Env string
+	// Source is the layer that supplied the value (empty if not provided)
+	Source Source
+	// File is a path to the file that supplied the value (file and dotenv
+	// sources only)
+	File string
+	// Key is a path of the value in the config file (file source only)
+	Key string
+}
+
+// origin describes where the value came from.
+func (f Field) origin() string {
+	switch f.Source {
+	case SourceDefault:
+		return "default value"
+	case SourceFile:
+		return fmt.Sprintf("file %s, key [%s]", f.File, f.Key)
+	case SourceDotEnv:
+		return fmt.Sprintf("env %s from %s", f.Env, f.File)
+	case SourceEnv:
+		return "env " + f.Env
+	case SourceFlag:
+		return "flag --" + f.Flag
+	default:
+		return ""
+	}
+}
+
+// describe adds the field path and the origin of the value to the message.
+func (f Field) describe(msg string) string {
+	if f.Path != "" {
+		msg = fmt.Sprintf("field [%s]: %s", f.Path, msg)
+	}
+	if origin := f.origin(); origin != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, origin)
+	}
+	return msg
+}
+
+// names returns the names of the flag and environment variable.
+func (f Field) names() string {
+	return fmt.Sprintf("[--%s] / [%s]", f.Flag, f.Env)
+}
+
+// missing describes the missing value.
+func (f Field) missing() string {
+	return fmt.Sprintf("missing required [--%s] argument/flag or [%s] env variable", f.Flag, f.Env)
+}
+
+// ParseError is returned when the value provided by the source can not be
+// used as a value of the config field.
+type ParseError struct {
+	Field
+	// Value is a raw value
+	Value string
+	// Type is the expected type
+	Type string
+	// Err is the cause (if any)
+	Err error
+}
+
+// Error returns the value, the type and the field details.
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("cannot use [%s] as type [%s]", e.Value, e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return e.describe(msg)
+}
+
+// Unwrap returns the cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedTypeError is returned when the type of the config field is not
+// supported.
+type UnsupportedTypeError struct {
+	Field
+	// Type is the type of the field
+	Type string
+}
+
+// Error returns the type and the field details.
+func (e *UnsupportedTypeError) Error() string {
+	return e.describe(fmt.Sprintf("Unsupported type [%s] in config", e.Type))
+}
+
+// MissingRequiredError is returned when the value of the required config field
+// has not been provided by any source.
+type MissingRequiredError struct {
+	Field
+}
+
+// Error returns the names of the flag and environment variable.
+func (e *MissingRequiredError) Error() string {
+	return e.describe(e.missing())
+}
+
+// RequirementError is returned when conditional (required_if, required_with)
+// or group (mutually_exclusive) requirement is not satisfied.
+type RequirementError struct {
+	Field
+	// Rule is the name of the tag
+	Rule string
+	// Other is the field the rule refers to
+	Other Field
+	// Value is the value of the other field the requirement depends on
+	// (required_if rule only)
+	Value string
+}
+
+// Error returns the rule and the names of both fields.
+func (e *RequirementError) Error() string {
+	var msg string
+	switch e.Rule {
+	case keyRequiredIfTag:
+		msg = fmt.Sprintf("%s: required if field [%s] (%s) is [%s]", e.missing(), e.Other.Path, e.Other.names(), e.Value)
+	case keyRequiredWithTag:
+		msg = fmt.Sprintf("%s: required with field [%s] (%s)", e.missing(), e.Other.Path, e.Other.names())
+	default:
+		msg = fmt.Sprintf("%s can not be used together with field [%s] (%s)", e.names(), e.Other.Path, e.Other.names())
+	}
+	return e.describe(msg)
+}
+
+// ValidationError is returned when the value of the config field does not
+// satisfy the validation rule provided with the struct tag.
+type ValidationError struct {
+	Field
+	// Rule is the name of the tag (e.g. "max") and Param is its value
+	Rule, Param string
+	// Value is the value of the field
+	Value string
+}
+
+// Error returns the value, the rule and the field details.
+func (e *ValidationError) Error() string {
+	var msg string
+	switch e.Rule {
+	case keyMinTag:
+		msg = "must be at least [%s]"
+	case keyMaxTag:
+		msg = "must be at most [%s]"
+	case keyMinLenTag:
+		msg = "length must be at least [%s]"
+	case keyMaxLenTag:
+		msg = "length must be at most [%s]"
+	case keyOneOfTag:
+		msg = "must be one of [%s]"
+	case keyPatternTag:
+		msg = "must match [%s]"
+	default:
+		msg = "does not satisfy [" + e.Rule + ":%q]"
+	}
+	return e.describe(fmt.Sprintf("value [%s] "+msg, e.Value, e.Param))
+}
+
+// FieldError is any other problem with the config field.
+type FieldError struct {
+	Field
+	// Err is the cause
+	Err error
+}
+
+// Error returns the cause and the field details.
+func (e *FieldError) Error() string {
+	return e.describe(e.Err.Error())
+}
+
+// Unwrap returns the cause.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FileError is a problem with the config (or dotenv) file.
+type FileError struct {
+	// File is a path to the file
+	File string
+	// Key is a path of the value in the file (if the problem is related to
+	// a particular key)
+	Key string
+	// Err is the cause
+	Err error
+}
+
+// Error returns the file and the cause.
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
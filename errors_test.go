@@ -0,0 +1,188 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Errors(t *testing.T) {
+	Convey("Errors", t, func() {
+		errs := Errors{
+			&ParseError{
+				Field: Field{Path: "Port", Flag: "port", Env: "APP_PORT", Source: SourceEnv},
+				Value: "abc",
+				Type:  "int",
+			},
+			errMissingRequired(Field{Path: "Name", Flag: "name", Env: "APP_NAME"}),
+			errCantSet,
+		}
+
+		Convey("single error is formatted as is", func() {
+			So(Errors{errCantSet}.Error(), ShouldEqual, errCantSet.Error())
+		})
+
+		Convey("multiple errors are formatted as a list", func() {
+			So(errs.Error(), ShouldEqual, "3 config errors:\n"+
+				"\t- field [Port]: cannot use [abc] as type [int] (env APP_PORT)\n"+
+				"\t- field [Name]: missing required [--name] argument/flag or [APP_NAME] env variable\n"+
+				"\t- Value can not be set")
+		})
+
+		Convey("errors.Is", func() {
+			So(errors.Is(errs, errCantSet), ShouldBeTrue)
+			So(errors.Is(errs, errInvalidReceiver), ShouldBeFalse)
+		})
+
+		Convey("errors.As", func() {
+			var parseErr *ParseError
+			So(errors.As(errs, &parseErr), ShouldBeTrue)
+			So(parseErr.Path, ShouldEqual, "Port")
+			So(parseErr.Env, ShouldEqual, "APP_PORT")
+			var missingErr *MissingRequiredError
+			So(errors.As(errs, &missingErr), ShouldBeTrue)
+			So(missingErr.Flag, ShouldEqual, "name")
+			var fileErr *FileError
+			So(errors.As(errs, &fileErr), ShouldBeFalse)
+		})
+
+		Convey("all the problems are collected in one pass", func() {
+			type testConfig struct {
+				Port    int `default:"abc"`
+				Timeout int `required:"true"`
+				Nested  struct {
+					Ratio float64
+				}
+			}
+			path := writeFile(t, "config.json", `{"unknown": 1, "nested": {"ratio": "high"}}`)
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs(nil),
+				WithJSONFile(path),
+				WithLookupEnv(mapEnv(map[string]string{"APP_PORT": "80x"})),
+			)
+			err := l.Load(new(testConfig))
+			So(err, ShouldResemble, Errors{
+				&ParseError{
+					Field: Field{Path: "Port", Flag: "port", Env: "APP_PORT", Source: SourceEnv},
+					Value: "80x",
+					Type:  "int",
+				},
+				errTypeMismatch(Field{
+					Path:   "Nested.Ratio",
+					Flag:   "nested-ratio",
+					Env:    "APP_NESTED_RATIO",
+					Source: SourceFile,
+					File:   path,
+					Key:    "nested.ratio",
+				}, "high", "float64"),
+				errUnknownKey(path, "unknown"),
+				errMissingRequired(Field{Path: "Timeout", Flag: "timeout", Env: "APP_TIMEOUT"}),
+			})
+		})
+	})
+}
+
+func Test_ErrorMessages(t *testing.T) {
+	type testCase struct {
+		title string
+		err   error
+		msg   string
+	}
+	var cases = []testCase{
+		{
+			"parse error with the cause",
+			&ParseError{Field: Field{Path: "Port", Flag: "port", Source: SourceFlag}, Value: "x", Type: "int", Err: errors.New("parse error")},
+			"field [Port]: cannot use [x] as type [int]: parse error (flag --port)",
+		},
+		{
+			"parse error from the file",
+			errTypeMismatch(Field{Path: "Port", Source: SourceFile, File: "app.yaml", Key: "db.port"}, "x", "int"),
+			"field [Port]: cannot use [x] as type [int] (file app.yaml, key [db.port])",
+		},
+		{
+			"parse error from the dotenv file",
+			&ParseError{Field: Field{Path: "Port", Env: "APP_PORT", Source: SourceDotEnv, File: ".env"}, Value: "x", Type: "int"},
+			"field [Port]: cannot use [x] as type [int] (env APP_PORT from .env)",
+		},
+		{
+			"unsupported type",
+			&UnsupportedTypeError{Field: Field{Path: "Ratio", Source: SourceDefault}, Type: "complex64"},
+			"field [Ratio]: Unsupported type [complex64] in config (default value)",
+		},
+		{
+			"required if",
+			&RequirementError{
+				Field: Field{Path: "TLS.Cert", Flag: "tls-cert", Env: "TLS_CERT"},
+				Rule:  keyRequiredIfTag,
+				Other: Field{Path: "TLS.Enabled", Flag: "tls-enabled", Env: "TLS_ENABLED"},
+				Value: "true",
+			},
+			"field [TLS.Cert]: missing required [--tls-cert] argument/flag or [TLS_CERT] env variable: required if field [TLS.Enabled] ([--tls-enabled] / [TLS_ENABLED]) is [true]",
+		},
+		{
+			"required with",
+			&RequirementError{
+				Field: Field{Path: "TLS.Key", Flag: "tls-key", Env: "TLS_KEY"},
+				Rule:  keyRequiredWithTag,
+				Other: Field{Path: "TLS.Cert", Flag: "tls-cert", Env: "TLS_CERT"},
+			},
+			"field [TLS.Key]: missing required [--tls-key] argument/flag or [TLS_KEY] env variable: required with field [TLS.Cert] ([--tls-cert] / [TLS_CERT])",
+		},
+		{
+			"mutually exclusive",
+			&RequirementError{
+				Field: Field{Path: "PasswordFile", Flag: "password-file", Env: "PASSWORD_FILE", Source: SourceFlag},
+				Rule:  keyExclusiveTag,
+				Other: Field{Path: "Password", Flag: "password", Env: "PASSWORD", Source: SourceEnv},
+			},
+			"field [PasswordFile]: [--password-file] / [PASSWORD_FILE] can not be used together with field [Password] ([--password] / [PASSWORD]) (flag --password-file)",
+		},
+		{
+			"validation error",
+			&ValidationError{Field: Field{Path: "Port", Flag: "port", Source: SourceFlag}, Rule: keyMaxTag, Param: "65535", Value: "70000"},
+			"field [Port]: value [70000] must be at most [65535] (flag --port)",
+		},
+		{
+			"validation error of the unset value",
+			&ValidationError{Field: Field{Path: "Hosts"}, Rule: keyMinLenTag, Param: "1", Value: ""},
+			"field [Hosts]: value [] length must be at least [1]",
+		},
+		{
+			"field error",
+			&FieldError{Field: Field{Path: "value"}, Err: errCantSet},
+			"field [value]: Value can not be set",
+		},
+		{
+			"file error",
+			errUnknownKey("app.yaml", "db.host"),
+			"app.yaml: unknown key [db.host]",
+		},
+	}
+	Convey("Error messages", t, func() {
+		for _, c := range cases {
+			Convey(c.title, func() {
+				So(c.err.Error(), ShouldEqual, c.msg)
+			})
+		}
+	})
+}
+
+func Test_FlagParseError(t *testing.T) {
+	Convey("flag parse error contains the field details", t, func() {
+		type testConfig struct {
+			Nested struct {
+				Port int `default:"80"`
+			}
+		}
+		l := NewLoader(WithEnvPrefix("APP"), WithArgs([]string{"--nested-port", "x"}), WithLookupEnv(mapEnv(nil)))
+		err := l.Load(new(testConfig))
+		var parseErr *ParseError
+		So(errors.As(err, &parseErr), ShouldBeTrue)
+		So(parseErr.Field, ShouldResemble, Field{Path: "Nested.Port", Flag: "nested-port", Env: "APP_NESTED_PORT", Source: SourceFlag})
+		So(parseErr.Value, ShouldEqual, "x")
+		So(parseErr.Type, ShouldEqual, "int")
+		So(parseErr.Err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileParser decodes the contents of a config file into a tree of values.
+type fileParser func(data []byte) (map[string]interface{}, error)
+
+// configFile is a config file to be loaded (a layer between default values
+// and environment variables).
+type configFile struct {
+	// path to the file
+	path string
+	// format of the file (used in error messages)
+	format string
+	// parse decodes file contents
+	parse fileParser
+	// untyped is true if all the values of the format are strings
+	untyped bool
+}
+
+// formats contains supported config file formats by file extension.
+var formats = map[string]configFile{
+	".json": {format: "JSON", parse: parseJSON},
+	".yaml": {format: "YAML", parse: parseYAML},
+	".yml":  {format: "YAML", parse: parseYAML},
+	".toml": {format: "TOML", parse: parseTOML},
+	".ini":  {format: "INI", parse: parseINI, untyped: true},
+}
+
+// dotEnvExt is an extension of dotenv files (handled separately from the other
+// formats, since dotenv entries are applied at the env variables layer)
+const dotEnvExt = ".env"
+
+// isDotEnv checks if the format of the file is unknown and its extension is
+// the dotenv one.
+func (f configFile) isDotEnv() bool {
+	return f.parse == nil && strings.EqualFold(filepath.Ext(f.path), dotEnvExt)
+}
+
+// resolve infers the format of the file from its extension (if not set).
+func (f configFile) resolve() (configFile, error) {
+	if f.parse != nil {
+		return f, nil
+	}
+	format, ok := formats[strings.ToLower(filepath.Ext(f.path))]
+	if !ok {
+		return f, errUnknownFormat(f.path)
+	}
+	format.path = f.path
+	return format, nil
+}
+
+// load reads and decodes the file.
+func (f configFile) load() (*tree, error) {
+	f, err := f.resolve()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := f.parse(data)
+	if err != nil {
+		return nil, errCantDecode(f.path, f.format, err)
+	}
+	return &tree{file: f.path, root: root, untyped: f.untyped, known: make(map[string]bool), invalid: make(map[string]bool)}, nil
+}
+
+// tree provides config values from a decoded config file. Object keys are
+// mapped onto the same nested-struct paths that nestedPrefix builds converted
+// with the naming strategy (case insensitive, like encoding/json does).
+type tree struct {
+	// file is a path to the source file
+	file string
+	// root is a top-level object
+	root map[string]interface{}
+	// untyped is true if all the values are strings
+	untyped bool
+	// naming converts Go names into keys
+	naming NamingStrategy
+	// known contains lower-cased paths of config fields (true) and nested
+	// structs (false) that have been looked up
+	known map[string]bool
+	// invalid contains paths of nested structs that do not match objects of
+	// the tree (to report each of them once)
+	invalid map[string]bool
+}
+
+// lookup finds a value for the config field by its path and converts it to a
+// string (the same format used by "default" tag and environment variables).
+// Returns the value and its key (empty if the value was not found).
+func (t *tree) lookup(path []string, f *fieldInfo) (string, string, error) {
+	var (
+		node interface{} = t.root
+		keys []string
+	)
+	names := t.naming.path(path)
+	for i, name := range names {
+		t.known[strings.ToLower(strings.Join(names[:i+1], "."))] = i == len(path)-1
+		if list, ok := node.([]interface{}); ok {
+			// element of the slice of nested structs
+			index, err := strconv.Atoi(name)
+			if err != nil || index < 0 || index >= len(list) {
+				return "", "", nil
+			}
+			keys = append(keys, name)
+			node = list[index]
+			continue
+		}
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			nested := strings.Join(path[:i], ".")
+			if t.invalid[nested] {
+				return "", "", nil
+			}
+			t.invalid[nested] = true
+			return "", "", errTypeMismatch(t.origin(nested, keys), node, "object")
+		}
+		key, ok := findKey(object, name)
+		if !ok {
+			return "", "", nil
+		}
+		keys = append(keys, key)
+		node = object[key]
+	}
+	if node == nil {
+		// null is treated as a missing value
+		return "", "", nil
+	}
+	key := strings.Join(keys, ".")
+	if str, ok := node.(string); ok && t.untyped {
+		return str, key, nil
+	}
+	if object, ok := node.(map[string]interface{}); ok && t.untyped && f.typ.Kind() == reflect.Map {
+		// map items of untyped files are passed as is
+		items := make([]string, 0, len(object))
+		for _, name := range sortedKeys(object) {
+			items = append(items, fmt.Sprintf("%s=%v", name, object[name]))
+		}
+		return strings.Join(items, comma), key, nil
+	}
+	value, ok := treeValue(node, f.typ, f.text)
+	if !ok {
+		origin := t.origin(f.Path, keys)
+		origin.Flag, origin.Env = f.Flag, f.Env
+		return "", "", f.mask(errTypeMismatch(origin, node, f.typ.String()).(*ParseError))
+	}
+	return value, key, nil
+}
+
+// length returns the length of the array that matches the slice of nested
+// structs by its path (zero if the value was not found).
+func (t *tree) length(path []string) (int, error) {
+	var (
+		node interface{} = t.root
+		keys []string
+	)
+	names := t.naming.path(path)
+	for _, name := range names {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			// reported by lookup
+			return 0, nil
+		}
+		key, ok := findKey(object, name)
+		if !ok {
+			return 0, nil
+		}
+		keys = append(keys, key)
+		node = object[key]
+	}
+	t.known[strings.ToLower(strings.Join(names, "."))] = false
+	switch list := node.(type) {
+	case nil:
+		return 0, nil
+	case []interface{}:
+		return len(list), nil
+	default:
+		// the value is reported as a whole (its keys are not checked)
+		t.known[strings.ToLower(strings.Join(names, "."))] = true
+		return 0, errTypeMismatch(t.origin(strings.Join(path, "."), keys), node, "array")
+	}
+}
+
+// origin describes the field with the value from the file.
+func (t *tree) origin(path string, keys []string) Field {
+	return Field{Path: path, Source: SourceFile, File: t.file, Key: strings.Join(keys, ".")}
+}
+
+// unknown checks the tree for keys that do not match any config field.
+func (t *tree) unknown() []error {
+	return t.walk(t.root, nil)
+}
+
+// walk recursively visits all the keys of the object.
+func (t *tree) walk(object map[string]interface{}, keys []string) (errs []error) {
+	for _, key := range sortedKeys(object) {
+		value, keys := object[key], append(keys[:len(keys):len(keys)], key)
+		isLeaf, ok := t.known[strings.ToLower(strings.Join(keys, "."))]
+		if !ok {
+			errs = append(errs, errUnknownKey(t.file, strings.Join(keys, ".")))
+			continue
+		}
+		if isLeaf {
+			continue
+		}
+		switch nested := value.(type) {
+		case map[string]interface{}:
+			errs = append(errs, t.walk(nested, keys)...)
+		case []interface{}:
+			// elements of the slice of nested structs
+			for i, item := range nested {
+				if object, ok := item.(map[string]interface{}); ok {
+					errs = append(errs, t.walk(object, append(keys[:len(keys):len(keys)], strconv.Itoa(i)))...)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// sortedKeys returns the keys of the object in sorted order.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// findKey looks for the key that matches the name (exact match is preferred).
+func findKey(object map[string]interface{}, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	for _, key := range sortedKeys(object) {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// treeValue converts decoded file value to a string according to the type of
+// the config field, slices are converted to comma-separated lists. Returns
+// false if the value does not match the type.
+func treeValue(value interface{}, typ, text reflect.Type) (string, bool) {
+	if typ.Kind() == reflect.Ptr && typ != text {
+		// pointer leaf
+		typ = typ.Elem()
+	}
+	if typ == text {
+		// custom types accept any scalar value as text
+		switch v := value.(type) {
+		case string, bool, json.Number, int, int64, uint64, float64:
+			return fmt.Sprint(v), true
+		case time.Time:
+			return v.Format(time.RFC3339Nano), true
+		}
+		return "", false
+	}
+	if typ.Kind() == reflect.Map {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		items := make([]string, 0, len(object))
+		for _, key := range sortedKeys(object) {
+			item, ok := treeValue(object[key], typ.Elem(), text)
+			if !ok {
+				return "", false
+			}
+			items = append(items, key+"="+item)
+		}
+		return strings.Join(items, comma), true
+	}
+	if typ.Kind() == reflect.Slice {
+		list, ok := value.([]interface{})
+		if !ok {
+			return "", false
+		}
+		items := make([]string, len(list))
+		for i, item := range list {
+			if items[i], ok = treeValue(item, typ.Elem(), text); !ok {
+				return "", false
+			}
+		}
+		return strings.Join(items, comma), true
+	}
+	switch v := value.(type) {
+	case string:
+		if typ.Kind() == reflect.String || typ == reflect.TypeOf(time.Duration(0)) {
+			return v, true
+		}
+	case time.Time:
+		if typ.Kind() == reflect.String {
+			return v.Format(time.RFC3339Nano), true
+		}
+	case bool:
+		if typ.Kind() == reflect.Bool {
+			return fmt.Sprint(v), true
+		}
+	case json.Number:
+		if isNumber(typ) {
+			return v.String(), true
+		}
+	case int, int64, uint64, float64:
+		if isNumber(typ) {
+			return fmt.Sprint(v), true
+		}
+	}
+	return "", false
+}
+
+// isNumber checks if the type is numeric (except time.Duration that is
+// represented by a string).
+func isNumber(typ reflect.Type) bool {
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// writeFile creates a temporary config file with provided contents.
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fileField describes the field of fileConfig with the value from the file.
+func fileField(file, key, path string) Field {
+	return Field{
+		Path:   path,
+		Flag:   strings.ToLower(path),
+		Env:    strings.ToUpper(path),
+		Source: SourceFile,
+		File:   file,
+		Key:    key,
+	}
+}
+
+type fileConfig struct {
+	Name     string `default:"default"`
+	Port     int
+	Debug    bool
+	Ratio    float64
+	Timeout  time.Duration
+	Hosts    []string
+	Ports    []int
+	Database struct {
+		User     string
+		Password string
+	}
+}
+
+func Test_JSONFile(t *testing.T) {
+	Convey("JSON config file", t, func() {
+		Convey("file values are applied between defaults and env variables", func() {
+			path := writeFile(t, "config.json", `{
+				"port": 8080,
+				"Debug": true,
+				"ratio": 0.5,
+				"timeout": "1m30s",
+				"hosts": ["foo", "bar"],
+				"ports": [1, 2, 3],
+				"database": {"user": "admin", "password": null}
+			}`)
+			conf := new(fileConfig)
+			l := NewLoader(
+				WithArgs(nil),
+				WithJSONFile(path),
+				WithLookupEnv(mapEnv(map[string]string{"DEBUG": "false"})),
+			)
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "default")
+			So(conf.Port, ShouldEqual, 8080)
+			So(conf.Debug, ShouldBeFalse)
+			So(conf.Ratio, ShouldEqual, 0.5)
+			So(conf.Timeout, ShouldEqual, 90*time.Second)
+			So(conf.Hosts, ShouldResemble, []string{"foo", "bar"})
+			So(conf.Ports, ShouldResemble, []int{1, 2, 3})
+			So(conf.Database.User, ShouldEqual, "admin")
+			So(conf.Database.Password, ShouldEqual, "")
+		})
+		Convey("files are applied in order they were added", func() {
+			first := writeFile(t, "first.json", `{"name": "first", "port": 1}`)
+			second := writeFile(t, "second.json", `{"name": "second"}`)
+			conf := new(fileConfig)
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(first), WithJSONFile(second))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "second")
+			So(conf.Port, ShouldEqual, 1)
+		})
+		Convey("missing file", func() {
+			l := NewLoader(WithArgs(nil), WithJSONFile(filepath.Join(t.TempDir(), "missing.json")))
+			So(l.Load(new(fileConfig)), ShouldNotBeNil)
+		})
+		Convey("invalid JSON", func() {
+			path := writeFile(t, "config.json", `{"port":`)
+			err := NewLoader(WithArgs(nil), WithJSONFile(path)).Load(new(fileConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, path+": cannot decode JSON file")
+		})
+		Convey("unknown key", func() {
+			path := writeFile(t, "config.json", `{"database": {"user": "admin", "host": "localhost"}}`)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errUnknownKey(path, "database.host")})
+		})
+		Convey("type mismatch", func() {
+			path := writeFile(t, "config.json", `{"port": "8080"}`)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errTypeMismatch(fileField(path, "port", "Port"), "8080", "int")})
+		})
+		Convey("slice item type mismatch", func() {
+			path := writeFile(t, "config.json", `{"ports": [1, "2"]}`)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errTypeMismatch(fileField(path, "ports", "Ports"), []interface{}{json.Number("1"), "2"}, "[]int")})
+		})
+		Convey("scalar instead of nested object", func() {
+			path := writeFile(t, "config.json", `{"database": "postgres"}`)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errTypeMismatch(Field{Path: "Database", Source: SourceFile, File: path, Key: "database"}, "postgres", "object")})
+		})
+	})
+}
+
+func Test_YAMLFile(t *testing.T) {
+	Convey("YAML config file", t, func() {
+		Convey("nested mappings and sequences", func() {
+			path := writeFile(t, "config.yaml", `
+# comment
+name: yaml
+port: 8080
+debug: true
+ratio: 0.25
+timeout: 2h
+hosts:
+  - foo
+  - bar
+ports: [1, 2, 3]
+database:
+  user: admin
+  password: ~
+`)
+			conf := new(fileConfig)
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithYAMLFile(path))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "yaml")
+			So(conf.Port, ShouldEqual, 8080)
+			So(conf.Debug, ShouldBeTrue)
+			So(conf.Ratio, ShouldEqual, 0.25)
+			So(conf.Timeout, ShouldEqual, 2*time.Hour)
+			So(conf.Hosts, ShouldResemble, []string{"foo", "bar"})
+			So(conf.Ports, ShouldResemble, []int{1, 2, 3})
+			So(conf.Database.User, ShouldEqual, "admin")
+			So(conf.Database.Password, ShouldEqual, "")
+		})
+		Convey("empty file", func() {
+			path := writeFile(t, "config.yaml", "")
+			conf := new(fileConfig)
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithYAMLFile(path)).Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "default")
+		})
+		Convey("invalid YAML", func() {
+			path := writeFile(t, "config.yaml", "port: [1, 2")
+			err := NewLoader(WithArgs(nil), WithYAMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, path+": cannot decode YAML file")
+		})
+		Convey("unknown key", func() {
+			path := writeFile(t, "config.yaml", "database:\n  1: one\n")
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithYAMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errUnknownKey(path, "database.1")})
+		})
+		Convey("type mismatch", func() {
+			path := writeFile(t, "config.yaml", "debug: yes please\n")
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithYAMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errTypeMismatch(fileField(path, "debug", "Debug"), "yes please", "bool")})
+		})
+	})
+}
+
+func Test_TOMLFile(t *testing.T) {
+	Convey("TOML config file", t, func() {
+		Convey("tables, arrays, datetime and durations", func() {
+			path := writeFile(t, "config.toml", `
+# comment
+name = 1979-05-27T07:32:00Z
+port = 8080
+debug = true
+ratio = 0.75
+timeout = "45s"
+hosts = ["foo", "bar"]
+ports = [1, 2, 3]
+
+[database]
+user = "admin"
+`)
+			conf := new(fileConfig)
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithTOMLFile(path))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "1979-05-27T07:32:00Z")
+			So(conf.Port, ShouldEqual, 8080)
+			So(conf.Debug, ShouldBeTrue)
+			So(conf.Ratio, ShouldEqual, 0.75)
+			So(conf.Timeout, ShouldEqual, 45*time.Second)
+			So(conf.Hosts, ShouldResemble, []string{"foo", "bar"})
+			So(conf.Ports, ShouldResemble, []int{1, 2, 3})
+			So(conf.Database.User, ShouldEqual, "admin")
+		})
+		Convey("decode error contains line and column", func() {
+			path := writeFile(t, "config.toml", "name = \"toml\"\nport = 80 80\n")
+			err := NewLoader(WithArgs(nil), WithTOMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, path+": cannot decode TOML file: line 2, column 10: expected a top-level item to end with a newline")
+		})
+		Convey("syntax error at EOF", func() {
+			path := writeFile(t, "config.toml", "a = ")
+			err := NewLoader(WithArgs(nil), WithTOMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, path+": cannot decode TOML file: line 1, column 5: unexpected EOF; expected value")
+			path = writeFile(t, "config.toml", "a = 1\nb = ")
+			err = NewLoader(WithArgs(nil), WithTOMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, path+": cannot decode TOML file: line 2, column 5: unexpected EOF; expected value")
+		})
+		Convey("unknown table", func() {
+			path := writeFile(t, "config.toml", "[cache]\nsize = 1\n")
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithTOMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errUnknownKey(path, "cache")})
+		})
+		Convey("type mismatch", func() {
+			path := writeFile(t, "config.toml", "timeout = 45\n")
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithTOMLFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errTypeMismatch(fileField(path, "timeout", "Timeout"), int64(45), "time.Duration")})
+		})
+	})
+}
+
+func Test_INIFile(t *testing.T) {
+	Convey("INI config file", t, func() {
+		Convey("sections and string values", func() {
+			path := writeFile(t, "config.ini", `
+; comment
+name = "ini"
+port: 8080
+debug = true
+hosts = foo,bar
+
+# comment
+[database]
+user = 'admin'
+`)
+			conf := new(fileConfig)
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithFile(path))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "ini")
+			So(conf.Port, ShouldEqual, 8080)
+			So(conf.Debug, ShouldBeTrue)
+			So(conf.Hosts, ShouldResemble, []string{"foo", "bar"})
+			So(conf.Database.User, ShouldEqual, "admin")
+		})
+		Convey("syntax errors", func() {
+			type testCase struct {
+				title    string
+				contents string
+				msg      string
+			}
+			var cases = []testCase{
+				{"unterminated section name", "[database\n", "line 1: unterminated section name"},
+				{"empty section name", "[database.]\n", "line 1: empty section name"},
+				{"section defined as a value", "database = x\n[database]\n", "line 2: section [database] is already defined as a value"},
+				{"missing equal sign", "\nname\n", "line 2: missing \"=\" after [name]"},
+				{"empty key", "= value\n", "line 1: empty key"},
+			}
+			for _, c := range cases {
+				Convey(c.title, func() {
+					_, err := parseINI([]byte(c.contents))
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, c.msg)
+				})
+			}
+		})
+		Convey("section instead of value", func() {
+			path := writeFile(t, "config.ini", "[name]\nfirst = x\n")
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithFile(path)).Load(new(fileConfig))
+			So(err, ShouldResemble, Errors{errTypeMismatch(fileField(path, "name", "Name"), map[string]interface{}{"first": "x"}, "string")})
+		})
+	})
+}
+
+func Test_WithFile(t *testing.T) {
+	Convey("format is inferred from the file extension", t, func() {
+		type testCase struct {
+			name     string
+			contents string
+		}
+		var cases = []testCase{
+			{"config.json", `{"port": 1}`},
+			{"config.yaml", "port: 1"},
+			{"config.YML", "port: 1"},
+			{"config.toml", "port = 1"},
+			{"config.ini", "port = 1"},
+		}
+		for _, c := range cases {
+			Convey(c.name, func() {
+				conf := new(fileConfig)
+				l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithFile(writeFile(t, c.name, c.contents)))
+				So(l.Load(conf), ShouldBeNil)
+				So(conf.Port, ShouldEqual, 1)
+			})
+		}
+		Convey("unknown format", func() {
+			path := writeFile(t, "config.xml", "<port>1</port>")
+			So(NewLoader(WithArgs(nil), WithFile(path)).Load(new(fileConfig)), ShouldResemble, Errors{errUnknownFormat(path)})
+		})
+	})
+}
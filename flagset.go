@@ -57,3 +57,87 @@ func (f *FlagSet) ArrayDurationVar(p *[]time.Duration, name string, value []time
 func (f *FlagSet) ArrayStringVar(p *[]string, name string, value []string, usage string) {
 	f.Var(newArrayString(value, p), name, usage)
 }
+
+// Int8Var defines an int8 flag with specified name, default value, and usage string.
+// The argument p points to an int8 variable in which to store the value of the flag.
+func (f *FlagSet) Int8Var(p *int8, name string, value int8, usage string) {
+	f.Var(newInt8Value(value, p), name, usage)
+}
+
+// Int16Var defines an int16 flag with specified name, default value, and usage string.
+// The argument p points to an int16 variable in which to store the value of the flag.
+func (f *FlagSet) Int16Var(p *int16, name string, value int16, usage string) {
+	f.Var(newInt16Value(value, p), name, usage)
+}
+
+// Int32Var defines an int32 flag with specified name, default value, and usage string.
+// The argument p points to an int32 variable in which to store the value of the flag.
+func (f *FlagSet) Int32Var(p *int32, name string, value int32, usage string) {
+	f.Var(newInt32Value(value, p), name, usage)
+}
+
+// Uint8Var defines an uint8 flag with specified name, default value, and usage string.
+// The argument p points to an uint8 variable in which to store the value of the flag.
+func (f *FlagSet) Uint8Var(p *uint8, name string, value uint8, usage string) {
+	f.Var(newUint8Value(value, p), name, usage)
+}
+
+// Uint16Var defines an uint16 flag with specified name, default value, and usage string.
+// The argument p points to an uint16 variable in which to store the value of the flag.
+func (f *FlagSet) Uint16Var(p *uint16, name string, value uint16, usage string) {
+	f.Var(newUint16Value(value, p), name, usage)
+}
+
+// Uint32Var defines an uint32 flag with specified name, default value, and usage string.
+// The argument p points to an uint32 variable in which to store the value of the flag.
+func (f *FlagSet) Uint32Var(p *uint32, name string, value uint32, usage string) {
+	f.Var(newUint32Value(value, p), name, usage)
+}
+
+// Float32Var defines an float32 flag with specified name, default value, and usage string.
+// The argument p points to an float32 variable in which to store the value of the flag.
+func (f *FlagSet) Float32Var(p *float32, name string, value float32, usage string) {
+	f.Var(newFloat32Value(value, p), name, usage)
+}
+
+// ArrayInt8Var defines an []int8 flag with specified name, default value, and usage string.
+// The argument p points to an []int8 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayInt8Var(p *[]int8, name string, value []int8, usage string) {
+	f.Var(newArrayInt8(value, p), name, usage)
+}
+
+// ArrayInt16Var defines an []int16 flag with specified name, default value, and usage string.
+// The argument p points to an []int16 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayInt16Var(p *[]int16, name string, value []int16, usage string) {
+	f.Var(newArrayInt16(value, p), name, usage)
+}
+
+// ArrayInt32Var defines an []int32 flag with specified name, default value, and usage string.
+// The argument p points to an []int32 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayInt32Var(p *[]int32, name string, value []int32, usage string) {
+	f.Var(newArrayInt32(value, p), name, usage)
+}
+
+// ArrayUint8Var defines an []uint8 flag with specified name, default value, and usage string.
+// The argument p points to an []uint8 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayUint8Var(p *[]uint8, name string, value []uint8, usage string) {
+	f.Var(newArrayUint8(value, p), name, usage)
+}
+
+// ArrayUint16Var defines an []uint16 flag with specified name, default value, and usage string.
+// The argument p points to an []uint16 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayUint16Var(p *[]uint16, name string, value []uint16, usage string) {
+	f.Var(newArrayUint16(value, p), name, usage)
+}
+
+// ArrayUint32Var defines an []uint32 flag with specified name, default value, and usage string.
+// The argument p points to an []uint32 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayUint32Var(p *[]uint32, name string, value []uint32, usage string) {
+	f.Var(newArrayUint32(value, p), name, usage)
+}
+
+// ArrayFloat32Var defines an []float32 flag with specified name, default value, and usage string.
+// The argument p points to an []float32 variable in which to store the value of the flag.
+func (f *FlagSet) ArrayFloat32Var(p *[]float32, name string, value []float32, usage string) {
+	f.Var(newArrayFloat32(value, p), name, usage)
+}
@@ -2,4 +2,8 @@ module github.com/tiny-go/config
 
 go 1.16
 
-require github.com/smartystreets/goconvey v1.6.4
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/smartystreets/goconvey v1.6.4
+	gopkg.in/yaml.v3 v3.0.1
+)
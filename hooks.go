@@ -0,0 +1,108 @@
+package config
+
+import "reflect"
+
+// Defaulter is implemented by config structs (or nested structs) that set
+// default values in code. SetDefaults is called before loading (nested structs
+// first, so the parent can override their defaults), the values it sets have
+// the lowest priority.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Validator is implemented by config structs (or nested structs) that check
+// cross-field rules. Validate is called after loading (nested structs first),
+// the error is reported with the path of the struct.
+type Validator interface {
+	Validate() error
+}
+
+// Finalizer is implemented by config structs (or nested structs) that derive
+// computed fields. Finalize is called after successful validation (nested
+// structs first).
+type Finalizer interface {
+	Finalize() error
+}
+
+var (
+	defaulterType = reflect.TypeOf((*Defaulter)(nil)).Elem()
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+	finalizerType = reflect.TypeOf((*Finalizer)(nil)).Elem()
+)
+
+// structInfo describes a config struct (or nested struct) visited by
+// initConfig.
+type structInfo struct {
+	// path is a Go path of the struct (empty for the config itself)
+	path string
+	// ptr is a pointer to the struct
+	ptr reflect.Value
+	// parent is a pointer to the struct the embedded struct is embedded into
+	// (the hooks are promoted to it)
+	parent reflect.Value
+}
+
+// promoted checks if the hook of the embedded struct is promoted to the parent
+// (then it is called by the parent).
+func (st structInfo) promoted(hook reflect.Type) bool {
+	return st.parent.IsValid() && st.parent.Type().Implements(hook)
+}
+
+// setDefaults calls SetDefaults() of the struct ptr points to and its nested
+// structs.
+func setDefaults(ptr reflect.Value) {
+	c := ptr.Elem()
+	for i := 0; i < c.NumField(); i++ {
+		field, structField := c.Field(i), c.Type().Field(i)
+		if field.Kind() != reflect.Struct || isIgnored(structField) || !field.Addr().CanInterface() || isCustom(field.Type()) {
+			continue
+		}
+		if structField.Anonymous && ptr.Type().Implements(defaulterType) {
+			// promoted to the parent
+			continue
+		}
+		setDefaults(field.Addr())
+	}
+	if d, ok := ptr.Interface().(Defaulter); ok {
+		d.SetDefaults()
+	}
+}
+
+// addStruct keeps the struct to call its hooks after loading.
+func (s *loadState) addStruct(ptr reflect.Value, goPath string) {
+	if ptr.CanInterface() {
+		s.structs = append(s.structs, structInfo{path: goPath, ptr: ptr})
+	}
+}
+
+// embed marks the struct as embedded into the parent.
+func (s *loadState) embed(ptr, parent reflect.Value) {
+	for i := len(s.structs) - 1; i >= 0; i-- {
+		if st := &s.structs[i]; st.ptr.Type() == ptr.Type() && st.ptr.Pointer() == ptr.Pointer() {
+			st.parent = parent
+			return
+		}
+	}
+}
+
+// runHooks calls Validate() of the config structs and then (if there are no
+// errors) Finalize().
+func (s *loadState) runHooks() {
+	for _, st := range s.structs {
+		if v, ok := st.ptr.Interface().(Validator); ok && !st.promoted(validatorType) {
+			if err := v.Validate(); err != nil {
+				s.errs = append(s.errs, &FieldError{Field: Field{Path: st.path}, Err: err})
+			}
+		}
+	}
+	if len(s.errs) != 0 {
+		return
+	}
+	for _, st := range s.structs {
+		if f, ok := st.ptr.Interface().(Finalizer); ok && !st.promoted(finalizerType) {
+			if err := f.Finalize(); err != nil {
+				s.errs = append(s.errs, &FieldError{Field: Field{Path: st.path}, Err: err})
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	errCertRequired = errors.New("cert is required if TLS is enabled")
+	// hooksCalls records the hooks called by the loader
+	hooksCalls []string
+)
+
+type hooksTLS struct {
+	Enabled bool
+	Cert    string
+	Port    int
+}
+
+func (t *hooksTLS) SetDefaults() {
+	t.Port = 443
+}
+
+func (t *hooksTLS) Validate() error {
+	if t.Enabled && t.Cert == "" {
+		return errCertRequired
+	}
+	return nil
+}
+
+type hooksConfig struct {
+	Host string
+	Port int `default:"80"`
+	TLS  hooksTLS
+	Addr string
+}
+
+func (c *hooksConfig) SetDefaults() {
+	hooksCalls = append(hooksCalls, "SetDefaults")
+	c.Host = "localhost"
+	c.TLS.Port = 8443
+}
+
+func (c *hooksConfig) Validate() error {
+	hooksCalls = append(hooksCalls, "Validate")
+	return nil
+}
+
+func (c *hooksConfig) Finalize() error {
+	hooksCalls = append(hooksCalls, "Finalize")
+	c.Addr = fmt.Sprintf("%s:%d", c.Host, c.Port)
+	if c.TLS.Enabled {
+		c.Addr = fmt.Sprintf("%s:%d", c.Host, c.TLS.Port)
+	}
+	return nil
+}
+
+func Test_Loader_Hooks(t *testing.T) {
+	Convey("Lifecycle hooks", t, func() {
+		hooksCalls = nil
+		Convey("hooks are called in order", func() {
+			conf := new(hooksConfig)
+			l := NewLoader(WithArgs([]string{"-port", "8080"}), WithLookupEnv(mapEnv(nil)))
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(hooksCalls, ShouldResemble, []string{"SetDefaults", "Validate", "Finalize"})
+			So(conf.Host, ShouldEqual, "localhost")
+			So(conf.Addr, ShouldEqual, "localhost:8080")
+			Convey("parent overrides defaults of nested structs", func() {
+				So(conf.TLS.Port, ShouldEqual, 8443)
+			})
+			Convey("values set by SetDefaults are reported as defaults", func() {
+				origin, ok := report.Provenance("Host")
+				So(ok, ShouldBeTrue)
+				So(origin.Source, ShouldEqual, SourceDefault)
+				So(origin.Value, ShouldEqual, "localhost")
+			})
+		})
+		Convey("values set by SetDefaults are overridden by the sources", func() {
+			conf := new(hooksConfig)
+			l := NewLoader(WithArgs([]string{"-tls-enabled", "-tls-cert", "cert.pem"}), WithLookupEnv(mapEnv(map[string]string{"HOST": "example.com"})))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Addr, ShouldEqual, "example.com:8443")
+		})
+		Convey("validation error of nested struct", func() {
+			conf := new(hooksConfig)
+			l := NewLoader(WithArgs([]string{"-tls-enabled"}), WithLookupEnv(mapEnv(nil)))
+			err := l.Load(conf)
+			So(err, ShouldResemble, Errors{&FieldError{Field: Field{Path: "TLS"}, Err: errCertRequired}})
+			So(errors.Is(err, errCertRequired), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "field [TLS]: cert is required if TLS is enabled")
+			Convey("Finalize is not called", func() {
+				So(hooksCalls, ShouldResemble, []string{"SetDefaults", "Validate"})
+				So(conf.Addr, ShouldEqual, "")
+			})
+		})
+		Convey("values set before loading are not parsed back", func() {
+			type prefilled struct {
+				Hosts  []string
+				Labels map[string]string
+				Port   *int
+			}
+			port := 8080
+			conf := &prefilled{Hosts: []string{"a,b"}, Labels: map[string]string{"k": "x,y=z"}, Port: &port}
+			report, err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Hosts, ShouldResemble, []string{"a,b"})
+			So(conf.Labels, ShouldResemble, map[string]string{"k": "x,y=z"})
+			So(conf.Port, ShouldEqual, &port)
+			origin, ok := report.Provenance("Hosts")
+			So(ok, ShouldBeTrue)
+			So(origin.Source, ShouldEqual, SourceDefault)
+			Convey("but are overridden by the sources", func() {
+				conf := &prefilled{Hosts: []string{"a,b"}}
+				err := NewLoader(WithArgs([]string{"--hosts", "c"}), WithLookupEnv(mapEnv(nil))).Load(conf)
+				So(err, ShouldBeNil)
+				So(conf.Hosts, ShouldResemble, []string{"c"})
+			})
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseINI decodes INI config file. Sections (including dotted sub-sections
+// like [database.replica]) are mapped onto nested structs, all the values are
+// strings in the same format as the "default" tag values.
+func parseINI(data []byte) (map[string]interface{}, error) {
+	var (
+		root    = make(map[string]interface{})
+		section = root
+	)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", line[0] == ';', line[0] == '#':
+			// empty line or comment
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section name", i+1)
+			}
+			section = root
+			for _, name := range strings.Split(line[1:len(line)-1], ".") {
+				if name = strings.TrimSpace(name); name == "" {
+					return nil, fmt.Errorf("line %d: empty section name", i+1)
+				}
+				nested, ok := section[name].(map[string]interface{})
+				if !ok {
+					if _, exists := section[name]; exists {
+						return nil, fmt.Errorf("line %d: section [%s] is already defined as a value", i+1, name)
+					}
+					nested = make(map[string]interface{})
+					section[name] = nested
+				}
+				section = nested
+			}
+		default:
+			sep := strings.IndexAny(line, "=:")
+			if sep < 0 {
+				return nil, fmt.Errorf("line %d: missing \"=\" after [%s]", i+1, line)
+			}
+			key := strings.TrimSpace(line[:sep])
+			if key == "" {
+				return nil, fmt.Errorf("line %d: empty key", i+1)
+			}
+			section[key] = unquote(strings.TrimSpace(line[sep+1:]))
+		}
+	}
+	return root, nil
+}
+
+// unquote removes matching single or double quotes around the value.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
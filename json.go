@@ -0,0 +1,18 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// parseJSON decodes JSON config file (numbers are decoded as json.Number to
+// keep their original representation).
+func parseJSON(data []byte) (map[string]interface{}, error) {
+	var root map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
@@ -0,0 +1,488 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Loader loads config values into a struct. Unlike Init() func it does not
+// touch any package-level variables, all the state is kept locally within a
+// single Load() call, so it is safe to load different configs concurrently.
+type Loader struct {
+	// envPrefix is a prefix in the beginning of environment variable name
+	envPrefix string
+	// args are command line arguments to be parsed
+	args []string
+	// lookupEnv retrieves the value of the environment variable
+	lookupEnv func(string) (string, bool)
+	// environ lists the environment variables (in "key=value" form)
+	environ func() []string
+	// flagSetName is a name of the FlagSet
+	flagSetName string
+	// errorHandling defines how to handle flag parsing errors
+	errorHandling flag.ErrorHandling
+	// files are config files to be loaded (in order of priority, from low to high)
+	files []configFile
+	// dotEnvFiles are dotenv files to be loaded (in order of priority, from low to high)
+	dotEnvFiles []string
+	// configFlag is a name of the flag that points at the config file
+	configFlag string
+	// output is a destination for usage and error messages of the FlagSet
+	output io.Writer
+	// allowEmptyEnv is true if empty env variables override lower layers
+	allowEmptyEnv bool
+	// decoders are registered decoders of custom types
+	decoders map[reflect.Type]DecoderFunc
+	// flagNaming, envNaming and keyNaming define the names of flags, env
+	// variables and keys of config files
+	flagNaming, envNaming, keyNaming NamingStrategy
+}
+
+// WithFlagNaming sets the naming strategy of flags (Lowercase by default).
+func WithFlagNaming(naming NamingStrategy) Option {
+	return func(l *Loader) { l.flagNaming = naming }
+}
+
+// WithEnvNaming sets the naming strategy of env variables and dotenv entries,
+// the env prefix is converted as well (Uppercase by default).
+func WithEnvNaming(naming NamingStrategy) Option {
+	return func(l *Loader) { l.envNaming = naming }
+}
+
+// WithKeyNaming sets the naming strategy of the keys of config files
+// (Lowercase by default), the keys are matched case insensitive.
+func WithKeyNaming(naming NamingStrategy) Option {
+	return func(l *Loader) { l.keyNaming = naming }
+}
+
+// Option is a functional option that configures the Loader.
+type Option func(*Loader)
+
+// WithEnvPrefix sets a prefix in the beginning of environment variable name
+// (used to easily differentiate variables of your application).
+func WithEnvPrefix(prefix string) Option {
+	return func(l *Loader) { l.envPrefix = prefix }
+}
+
+// WithArgs sets command line arguments to be parsed (os.Args[1:] by default).
+func WithArgs(args []string) Option {
+	return func(l *Loader) { l.args = args }
+}
+
+// WithLookupEnv sets the func to retrieve environment variables (os.LookupEnv
+// by default).
+func WithLookupEnv(lookupEnv func(string) (string, bool)) Option {
+	return func(l *Loader) { l.lookupEnv = lookupEnv }
+}
+
+// WithEnviron sets the func to list environment variables in "key=value" form
+// (os.Environ by default), it is used to collect map items for the fields
+// tagged with envscan and indexed elements of slices. Only the variables
+// resolved with the lookup func (see WithLookupEnv) are used.
+func WithEnviron(environ func() []string) Option {
+	return func(l *Loader) { l.environ = environ }
+}
+
+// WithFlagSetName sets the name of the FlagSet ("config" by default).
+func WithFlagSetName(name string) Option {
+	return func(l *Loader) { l.flagSetName = name }
+}
+
+// WithErrorHandling sets the error handling property of the FlagSet
+// (flag.ContinueOnError by default).
+func WithErrorHandling(errorHandling flag.ErrorHandling) Option {
+	return func(l *Loader) { l.errorHandling = errorHandling }
+}
+
+// WithJSONFile adds a JSON config file. Config files are applied in order they
+// were added: after default values and before environment variables.
+func WithJSONFile(path string) Option {
+	return func(l *Loader) {
+		l.files = append(l.files, configFile{path: path, format: "JSON", parse: parseJSON})
+	}
+}
+
+// WithYAMLFile adds a YAML config file. Nested mappings are mapped onto nested
+// structs and sequences onto slices.
+func WithYAMLFile(path string) Option {
+	return func(l *Loader) {
+		l.files = append(l.files, configFile{path: path, format: "YAML", parse: parseYAML})
+	}
+}
+
+// WithTOMLFile adds a TOML config file. Tables are mapped onto nested structs and
+// arrays onto slices, native datetime values can be assigned to string fields
+// (in RFC 3339 format) and durations are expected to be strings.
+func WithTOMLFile(path string) Option {
+	return func(l *Loader) {
+		l.files = append(l.files, configFile{path: path, format: "TOML", parse: parseTOML})
+	}
+}
+
+// WithDotEnvFiles adds dotenv files (missing files are skipped). Entries are
+// resolved with the same names as environment variables, the following files
+// override the previous ones and the real environment overrides them all. The
+// process environment is never modified.
+func WithDotEnvFiles(files ...string) Option {
+	return func(l *Loader) { l.dotEnvFiles = append(l.dotEnvFiles, files...) }
+}
+
+// WithFile adds a config file, the format is inferred from its extension:
+// ".json", ".yaml", ".yml", ".toml", ".ini" or ".env" (dotenv files are applied
+// the same way as those added with WithDotEnvFiles(), but they have to exist).
+func WithFile(path string) Option {
+	return func(l *Loader) { l.files = append(l.files, configFile{path: path}) }
+}
+
+// WithConfigFlag sets the name of the flag that points at the config file
+// ("config" by default, empty name disables the flag). The flag is parsed
+// before the other flags, the file is added after the files provided with
+// options and its format is inferred from the extension (see WithFile).
+func WithConfigFlag(name string) Option {
+	return func(l *Loader) { l.configFlag = name }
+}
+
+// WithOutput sets the destination for usage and error messages of the flags
+// (os.Stderr by default).
+func WithOutput(output io.Writer) Option {
+	return func(l *Loader) { l.output = output }
+}
+
+// WithAllowEmptyEnv sets whether empty env variables (and dotenv entries) are
+// treated as provided values: they override lower layers (e.g. clear the
+// default value) and satisfy the requirements. By default empty variables are
+// ignored like unset ones, allowempty tag overrides the option for the field.
+func WithAllowEmptyEnv(allow bool) Option {
+	return func(l *Loader) { l.allowEmptyEnv = allow }
+}
+
+// NewLoader creates a new Loader with provided options.
+func NewLoader(options ...Option) *Loader {
+	l := &Loader{
+		args:          args,
+		lookupEnv:     os.LookupEnv,
+		environ:       os.Environ,
+		flagSetName:   "config",
+		errorHandling: flag.ContinueOnError,
+		configFlag:    "config",
+		flagNaming:    Lowercase,
+		envNaming:     Uppercase,
+		keyNaming:     Lowercase,
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// loadState holds the state of a single Load() call.
+type loadState struct {
+	*Loader
+	// flagSet is a set of flags defined for config fields
+	flagSet *FlagSet
+	// fields are visited config fields
+	fields []*fieldInfo
+	// structs are visited config structs (nested structs first)
+	structs []structInfo
+	// sections are pointers to nested structs allocated on demand
+	sections []section
+	// flags, envs and keys contain config fields by the names of flags, env
+	// variables and (lower-cased) keys of config files
+	flags, envs, keys map[string]*fieldInfo
+	// files are decoded config files
+	files []*tree
+	// dotEnv contains entries of dotenv files and dotEnvOrigins contains the
+	// files they come from
+	dotEnv, dotEnvOrigins map[string]string
+	// errs contains all the problems found
+	errs Errors
+	// flagErr is a flag parse error
+	flagErr error
+}
+
+// fieldInfo describes a config field visited by initConfig.
+type fieldInfo struct {
+	Field
+	// typ is a type of the field
+	typ reflect.Type
+	// text is the type parsed from text by the decoder or the interface (the
+	// type of the field or its items), nil for built-in types
+	text reflect.Type
+	// rv is the field value
+	rv reflect.Value
+	// tag is the field tag
+	tag reflect.StructTag
+	// required is true if the value has to be provided
+	required bool
+	// secret is true if the value should not be revealed
+	secret bool
+	// failed is true if there is an error related to the field
+	failed bool
+	// value is a raw value supplied by the source
+	value string
+}
+
+// set assigns the value supplied by the source (overrides the previous one).
+func (f *fieldInfo) set(origin Field, value string) {
+	f.Source, f.File, f.Key = origin.Source, origin.File, origin.Key
+	f.value = value
+}
+
+// mask hides the raw value of the secret field in the parse error (the cause
+// may contain the value as well, so it is replaced with a generic one).
+func (f *fieldInfo) mask(e *ParseError) *ParseError {
+	if f.secret {
+		e.Value = secretMask
+		if e.Err != nil {
+			e.Err = errInvalidValue(e.Type)
+		}
+	}
+	return e
+}
+
+// fail adds the error related to the config field.
+func (s *loadState) fail(f *fieldInfo, err error) {
+	f.failed = true
+	switch e := err.(type) {
+	case *ParseError:
+		e.Field = f.Field
+		f.mask(e)
+	case *UnsupportedTypeError:
+		e.Field = f.Field
+	default:
+		err = &FieldError{Field: f.Field, Err: err}
+	}
+	s.errs = append(s.errs, err)
+}
+
+// wrapFlag wraps the value of the flag defined for the config field to keep
+// the raw value and the parse error.
+func (s *loadState) wrapFlag(f *fieldInfo) {
+	fl := s.flagSet.Lookup(f.Flag)
+	fl.Value = &fieldFlag{Value: fl.Value, state: s, field: f}
+	fl.Usage = f.usage()
+}
+
+// fieldFlag is a flag.Value of the config field.
+type fieldFlag struct {
+	flag.Value
+	state *loadState
+	field *fieldInfo
+}
+
+// Set parses the value and marks the field as provided with a flag.
+func (v *fieldFlag) Set(value string) error {
+	v.field.set(Field{Source: SourceFlag}, value)
+	if err := v.Value.Set(value); err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.Field = v.field.Field
+			v.state.flagErr = v.field.mask(e)
+		} else {
+			v.state.flagErr = v.field.mask(&ParseError{Field: v.field.Field, Value: value, Type: v.field.typ.String(), Err: err})
+		}
+		return err
+	}
+	return nil
+}
+
+// String returns the value of the flag (zero value is used by the FlagSet to
+// check if the default value is empty).
+func (v *fieldFlag) String() string {
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+// IsBoolFlag allows to use the flag without the value if it is boolean.
+func (v *fieldFlag) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// Load config values into the struct c points to. All the problems found are
+// returned at once as Errors.
+func (l *Loader) Load(c interface{}) error {
+	_, err := l.LoadReport(c)
+	return err
+}
+
+// LoadReport loads config values like Load() does and returns the Report that
+// describes where the value of every config field came from (the report is
+// returned even if loading has failed, unless c is not a pointer to a struct).
+func (l *Loader) LoadReport(c interface{}) (*Report, error) {
+	// check argument type (only pointer to struct is supported)
+	rv := reflect.ValueOf(c)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errInvalidReceiver
+	}
+	s := &loadState{
+		Loader:  l,
+		flagSet: NewFlagSet(l.flagSetName, flag.ContinueOnError),
+		flags:   make(map[string]*fieldInfo),
+		envs:    make(map[string]*fieldInfo),
+		keys:    make(map[string]*fieldInfo),
+	}
+	// error messages of the FlagSet contain raw values, so they are replaced
+	// with the errors of the loader (see parseFlags)
+	s.flagSet.SetOutput(io.Discard)
+	s.flagSet.Usage = func() {}
+	err := s.load(rv)
+	return newReport(s.fields), err
+}
+
+// parseFlags parses the flags, the problems are reported to the output the
+// same way the FlagSet does (raw values of secret fields are masked).
+func (s *loadState) parseFlags() error {
+	err := s.flagSet.Parse(s.args)
+	if err == nil {
+		return nil
+	}
+	if err != flag.ErrHelp {
+		if s.flagErr == nil {
+			s.flagErr = errInvalidFlag(err)
+		}
+		err = s.flagErr
+		fmt.Fprintln(s.writer(), err)
+	}
+	s.printUsage()
+	switch s.errorHandling {
+	case flag.ExitOnError:
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// writer returns the destination for usage and error messages (os.Stderr by
+// default).
+func (s *loadState) writer() io.Writer {
+	if s.output == nil {
+		return os.Stderr
+	}
+	return s.output
+}
+
+// scanEnvNames lists the env variables with the prefix (see WithEnviron), the
+// values are retrieved with lookupEnv (unresolved variables are skipped).
+func (s *loadState) scanEnvNames(prefix string) map[string]string {
+	vars := make(map[string]string)
+	for _, entry := range s.environ() {
+		name := strings.SplitN(entry, "=", 2)[0]
+		if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		if value, ok := s.lookupEnv(name); ok {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// load config values into the struct.
+func (s *loadState) load(rv reflect.Value) error {
+	s.loadFiles()
+	setDefaults(rv)
+	s.initConfig(rv, emptyPrefix, "")
+	// check config files for keys that do not match any config field
+	for _, file := range s.files {
+		s.errs = append(s.errs, file.unknown()...)
+	}
+	if s.configFlag != "" {
+		s.flagSet.String(s.configFlag, "", configFlagUsage)
+	}
+	// parse flags
+	if err := s.parseFlags(); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		// values of the flags after the invalid one are unknown, so required
+		// values can not be checked
+		return append(s.errs, err)
+	}
+	// assign the sections with provided values
+	s.finishSections()
+	// find missing required values
+	for _, f := range s.fields {
+		if f.required && f.Source == "" && !f.failed {
+			f.failed = true
+			s.errs = append(s.errs, errMissingRequired(f.Field))
+		}
+	}
+	// check conditional and group requirements
+	s.checkRequirements()
+	// validate the values provided by all the layers
+	s.validate()
+	// call Validate() and Finalize() hooks of config structs
+	s.runHooks()
+	if len(s.errs) != 0 {
+		return s.errs
+	}
+	// success
+	return nil
+}
+
+// loadFiles reads config and dotenv files (including the one provided with
+// the config file flag), the files that can not be loaded are skipped.
+func (s *loadState) loadFiles() {
+	files, dotEnvFiles := s.Loader.files, s.dotEnvFiles
+	if s.configFlag != "" {
+		if path, ok := flagValue(s.args, s.configFlag); ok {
+			files = append(files[:len(files):len(files)], configFile{path: path})
+		}
+	}
+	for _, file := range files {
+		if file.isDotEnv() {
+			// unlike optional dotenv files this one has to exist
+			if _, err := os.Stat(file.path); err != nil {
+				s.errs = append(s.errs, err)
+				continue
+			}
+			dotEnvFiles = append(dotEnvFiles[:len(dotEnvFiles):len(dotEnvFiles)], file.path)
+			continue
+		}
+		t, err := file.load()
+		if err != nil {
+			s.errs = append(s.errs, err)
+			continue
+		}
+		t.naming = s.keyNaming
+		s.files = append(s.files, t)
+	}
+	dotEnv, origins, err := loadDotEnv(dotEnvFiles, s.lookupEnv)
+	if err != nil {
+		s.errs = append(s.errs, err)
+	}
+	s.dotEnv, s.dotEnvOrigins = dotEnv, origins
+}
+
+// flagValue looks for the value of the flag in command line arguments before
+// they are parsed (the last one wins, like with the FlagSet).
+func flagValue(args []string, name string) (value string, found bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		arg = strings.TrimPrefix(arg[1:], "-")
+		switch {
+		case arg == name && i+1 < len(args):
+			i++
+			value, found = args[i], true
+		case strings.HasPrefix(arg, name+"="):
+			value, found = arg[len(name)+1:], true
+		}
+	}
+	return value, found
+}
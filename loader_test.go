@@ -0,0 +1,374 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// mapEnv returns a lookup func that reads variables from the map.
+func mapEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+// withEnv provides the variables from the map to both lookupEnv and environ.
+func withEnv(env map[string]string) Option {
+	return func(l *Loader) {
+		l.lookupEnv = mapEnv(env)
+		l.environ = func() []string {
+			entries := make([]string, 0, len(env))
+			for key, value := range env {
+				entries = append(entries, key+"="+value)
+			}
+			return entries
+		}
+	}
+}
+
+func Test_NewLoader(t *testing.T) {
+	Convey("NewLoader", t, func() {
+		Convey("default options", func() {
+			l := NewLoader()
+			So(l.envPrefix, ShouldEqual, "")
+			So(l.args, ShouldResemble, args)
+			So(l.flagSetName, ShouldEqual, "config")
+			So(l.errorHandling, ShouldEqual, flag.ContinueOnError)
+			So(l.lookupEnv, ShouldNotBeNil)
+		})
+		Convey("custom options", func() {
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs([]string{"-value", "42"}),
+				WithLookupEnv(mapEnv(nil)),
+				WithFlagSetName("app"),
+				WithErrorHandling(flag.PanicOnError),
+			)
+			So(l.envPrefix, ShouldEqual, "APP")
+			So(l.args, ShouldResemble, []string{"-value", "42"})
+			So(l.flagSetName, ShouldEqual, "app")
+			So(l.errorHandling, ShouldEqual, flag.PanicOnError)
+		})
+	})
+}
+
+func Test_Loader_Load(t *testing.T) {
+	type testConfig struct {
+		Value  int    `default:"1"`
+		Name   string `required:"true"`
+		Nested struct {
+			Value int
+		}
+	}
+	Convey("Load", t, func() {
+		Convey("only pointer to struct is supported", func() {
+			So(NewLoader(WithArgs(nil)).Load(testConfig{}), ShouldEqual, errInvalidReceiver)
+		})
+		Convey("values by priority", func() {
+			conf := new(testConfig)
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs([]string{"-name", "flag"}),
+				WithLookupEnv(mapEnv(map[string]string{
+					"APP_NAME":         "env",
+					"APP_NESTED_VALUE": "42",
+				})),
+			)
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Value, ShouldEqual, 1)
+			So(conf.Name, ShouldEqual, "flag")
+			So(conf.Nested.Value, ShouldEqual, 42)
+		})
+		Convey("missing required value", func() {
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)))
+			So(l.Load(new(testConfig)), ShouldResemble, Errors{
+				errMissingRequired(Field{Path: "Name", Flag: "name", Env: "NAME"}),
+			})
+		})
+		Convey("concurrent loads with different prefixes", func() {
+			env := mapEnv(map[string]string{
+				"APP0_NAME": "app0",
+				"APP1_NAME": "app1",
+				"APP2_NAME": "app2",
+				"APP3_NAME": "app3",
+			})
+			var (
+				wg      sync.WaitGroup
+				configs = make([]testConfig, 4)
+				errs    = make([]error, 4)
+			)
+			for i := range configs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					l := NewLoader(WithEnvPrefix(fmt.Sprintf("APP%d", i)), WithArgs(nil), WithLookupEnv(env))
+					errs[i] = l.Load(&configs[i])
+				}(i)
+			}
+			wg.Wait()
+			for i := range configs {
+				So(errs[i], ShouldBeNil)
+				So(configs[i].Name, ShouldEqual, fmt.Sprintf("app%d", i))
+			}
+		})
+	})
+}
+
+func Test_flagValue(t *testing.T) {
+	type testCase struct {
+		title string
+		args  []string
+		value string
+		found bool
+	}
+	var cases = []testCase{
+		{"no arguments", nil, "", false},
+		{"single dash", []string{"-config", "app.yaml"}, "app.yaml", true},
+		{"double dash", []string{"--port", "80", "--config", "app.yaml"}, "app.yaml", true},
+		{"with equal sign", []string{"--config=app.yaml", "-v"}, "app.yaml", true},
+		{"the last one wins", []string{"-config", "first.yaml", "-config=second.yaml"}, "second.yaml", true},
+		{"missing value", []string{"-config"}, "", false},
+		{"different flag", []string{"-configuration", "app.yaml"}, "", false},
+		{"after terminator", []string{"--", "-config", "app.yaml"}, "", false},
+	}
+	Convey("Flag value", t, func() {
+		for _, c := range cases {
+			Convey(c.title, func() {
+				value, found := flagValue(c.args, "config")
+				So(value, ShouldEqual, c.value)
+				So(found, ShouldEqual, c.found)
+			})
+		}
+	})
+}
+
+func Test_Loader_ConfigFlag(t *testing.T) {
+	type testConfig struct {
+		Name string `default:"default"`
+		DB   struct {
+			Host string
+			Port int
+		}
+	}
+	Convey("config file flag", t, func() {
+		Convey("file is loaded before the flags are parsed", func() {
+			path := writeFile(t, "prod.yaml", "name: prod\ndb:\n  host: db.local\n  port: 5432\n")
+			conf := new(testConfig)
+			l := NewLoader(WithArgs([]string{"--config", path, "--db-port", "5433"}), WithLookupEnv(mapEnv(nil)))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "prod")
+			So(conf.DB.Host, ShouldEqual, "db.local")
+			So(conf.DB.Port, ShouldEqual, 5433)
+		})
+		Convey("file overrides the files provided with options", func() {
+			first := writeFile(t, "first.json", `{"name": "first", "db": {"port": 1}}`)
+			second := writeFile(t, "second.toml", "name = \"second\"\n")
+			conf := new(testConfig)
+			l := NewLoader(WithArgs([]string{"-settings=" + second}), WithLookupEnv(mapEnv(nil)), WithConfigFlag("settings"), WithFile(first))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "second")
+			So(conf.DB.Port, ShouldEqual, 1)
+		})
+		Convey("dotenv file", func() {
+			path := writeFile(t, "prod.env", "APP_NAME=dotenv\n")
+			conf := new(testConfig)
+			l := NewLoader(WithEnvPrefix("APP"), WithArgs([]string{"-config", path}), WithLookupEnv(mapEnv(nil)))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "dotenv")
+		})
+		Convey("missing dotenv file", func() {
+			l := NewLoader(WithArgs([]string{"-config", filepath.Join(t.TempDir(), "missing.env")}))
+			So(l.Load(new(testConfig)), ShouldNotBeNil)
+		})
+		Convey("disabled flag", func() {
+			l := NewLoader(WithArgs([]string{"-config", "app.yaml"}), WithLookupEnv(mapEnv(nil)), WithConfigFlag(""))
+			So(l.Load(new(testConfig)), ShouldNotBeNil)
+		})
+		Convey("flag name is reserved", func() {
+			conf := &struct{ Config string }{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldResemble, Errors{
+				&FieldError{Field: Field{Path: "Config", Flag: "config", Env: "CONFIG"}, Err: errReservedFlag("config")},
+			})
+		})
+	})
+}
+
+func Test_Loader_AllowEmptyEnv(t *testing.T) {
+	type testConfig struct {
+		Proxy   string `default:"http://proxy.local" allowempty:"true"`
+		Name    string `default:"app"`
+		Token   string `required:"true" allowempty:"true"`
+		Timeout int    `default:"5"`
+	}
+	env := mapEnv(map[string]string{"PROXY": "", "NAME": "", "TOKEN": "", "TIMEOUT": ""})
+	Convey("Empty env variables", t, func() {
+		Convey("are ignored by default", func() {
+			conf := new(testConfig)
+			So(NewLoader(WithArgs(nil), WithLookupEnv(env)).Load(conf), ShouldBeNil)
+			So(conf.Proxy, ShouldEqual, "")
+			So(conf.Name, ShouldEqual, "app")
+			So(conf.Timeout, ShouldEqual, 5)
+		})
+		Convey("are allowed with the option", func() {
+			conf := new(testConfig)
+			l := NewLoader(WithArgs(nil), WithLookupEnv(env), WithAllowEmptyEnv(true))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "")
+			So(conf.Timeout, ShouldEqual, 0)
+		})
+		Convey("tag overrides the option", func() {
+			conf := &struct {
+				Name string `default:"app" allowempty:"false"`
+			}{}
+			l := NewLoader(WithArgs(nil), WithLookupEnv(env), WithAllowEmptyEnv(true))
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Name, ShouldEqual, "app")
+		})
+		Convey("empty dotenv entries", func() {
+			path := writeFile(t, ".env", "PROXY=\nNAME=\n")
+			conf := &struct {
+				Proxy string `default:"http://proxy.local" allowempty:"true"`
+				Name  string `default:"app"`
+			}{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithDotEnvFiles(path)).Load(conf), ShouldBeNil)
+			So(conf.Proxy, ShouldEqual, "")
+			So(conf.Name, ShouldEqual, "app")
+		})
+		Convey("missing required value", func() {
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)))
+			So(l.Load(new(testConfig)), ShouldResemble, Errors{
+				errMissingRequired(Field{Path: "Token", Flag: "token", Env: "TOKEN"}),
+			})
+		})
+		Convey("invalid tag", func() {
+			conf := &struct {
+				Name string `allowempty:"maybe"`
+			}{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(env)).Load(conf), ShouldResemble, Errors{
+				&FieldError{Field: Field{Path: "Name", Flag: "name", Env: "NAME"}, Err: errInvalidTag(keyAllowEmptyTag, "maybe")},
+			})
+		})
+	})
+}
+
+func Test_Loader_SizedNumbers(t *testing.T) {
+	type testConfig struct {
+		Port  uint16  `default:"8080"`
+		Level int8    `default:"-1"`
+		Ratio float32 `default:"0.5"`
+		IDs   []int32
+	}
+	Convey("Sized numbers", t, func() {
+		Convey("values from all the layers", func() {
+			conf := new(testConfig)
+			l := NewLoader(WithArgs([]string{"-ids", "1,2"}), WithLookupEnv(mapEnv(map[string]string{"PORT": "65535"})))
+			So(l.Load(conf), ShouldBeNil)
+			So(*conf, ShouldResemble, testConfig{Port: 65535, Level: -1, Ratio: 0.5, IDs: []int32{1, 2}})
+		})
+		Convey("overflow", func() {
+			l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(map[string]string{"PORT": "70000"})))
+			err := l.Load(new(testConfig))
+			So(err, ShouldResemble, Errors{&ParseError{
+				Field: Field{Path: "Port", Flag: "port", Env: "PORT", Source: SourceEnv},
+				Value: "70000",
+				Type:  "uint16",
+				Err:   errOutOfRange("70000", uint16(0), 16).(*ParseError).Err,
+			}})
+			So(err.Error(), ShouldEqual, "field [Port]: cannot use [70000] as type [uint16]: value out of range (16-bit) (env PORT)")
+		})
+		Convey("flag overflow", func() {
+			err := NewLoader(WithArgs([]string{"-level", "200"}), WithLookupEnv(mapEnv(nil))).Load(new(testConfig))
+			So(err.Error(), ShouldEqual, "field [Level]: cannot use [200] as type [int8]: value out of range (8-bit) (flag --level)")
+		})
+	})
+}
+
+type loaderCommon struct {
+	Host string `default:"localhost"`
+}
+
+func Test_Loader_IgnoredFields(t *testing.T) {
+	type testConfig struct {
+		loaderCommon
+		Port  int
+		Conns chan int       `config:"-"`
+		Peers []fmt.Stringer `config:"-"`
+		mu    sync.Mutex
+		cache map[string]interface{}
+	}
+	Convey("Ignored and unexported fields", t, func() {
+		Convey("are skipped", func() {
+			conf := new(testConfig)
+			report, err := NewLoader(
+				WithArgs([]string{"--port", "8080"}),
+				WithLookupEnv(mapEnv(map[string]string{"HOST": "example.com"})),
+			).LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Host, ShouldEqual, "example.com")
+			So(conf.Port, ShouldEqual, 8080)
+			So(conf.Conns, ShouldBeNil)
+			_, ok := report.Provenance("Conns")
+			So(ok, ShouldBeFalse)
+			_, ok = report.Provenance("mu")
+			So(ok, ShouldBeFalse)
+		})
+		Convey("have no flags", func() {
+			err := NewLoader(WithArgs([]string{"--conns", "1"}), WithLookupEnv(mapEnv(nil))).Load(new(testConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "flag provided but not defined: -conns")
+		})
+		Convey("exported fields of unsupported type are reported", func() {
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(&struct {
+				Conns chan int
+			}{})
+			So(err, ShouldResemble, Errors{&UnsupportedTypeError{
+				Field: Field{Path: "Conns", Flag: "conns", Env: "CONNS"},
+				Type:  "chan",
+			}})
+		})
+	})
+}
+
+func Test_Loader_NameCollisions(t *testing.T) {
+	Convey("Name collisions", t, func() {
+		Convey("flag tag colliding with generated name", func() {
+			type testConfig struct {
+				DB struct {
+					Port int
+				}
+				Port int `keyFlag:"db-port"`
+			}
+			var err error
+			So(func() { err = NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(new(testConfig)) }, ShouldNotPanic)
+			So(err, ShouldResemble, Errors{&FieldError{
+				Field: Field{Path: "Port", Flag: "db-port", Env: "PORT"},
+				Err:   errNameCollision("flag [--db-port]", "DB.Port"),
+			}})
+			So(err.Error(), ShouldEqual, "field [Port]: flag [--db-port] collides with field [DB.Port]")
+		})
+		Convey("env tag colliding with generated name", func() {
+			type testConfig struct {
+				Host  string
+				Proxy string `env:"APP_HOST"`
+			}
+			err := NewLoader(WithEnvPrefix("APP"), WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(new(testConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "field [Proxy]: env [APP_HOST] collides with field [Host]")
+		})
+		Convey("all the collisions are reported", func() {
+			type testConfig struct {
+				A string `env:"X"`
+				B string `env:"X"`
+				C string `env:"X"`
+			}
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(new(testConfig))
+			So(err, ShouldHaveLength, 2)
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// mapValue is a flag.Value of map[string]T field, "k=v,k2=v2" items are parsed
+// with the same per-type parsing as the fields of type T. The flag can be
+// repeated: the first one replaces the value of lower layers, the following
+// ones add the items.
+type mapValue struct {
+	ptr   reflect.Value
+	parse func(string) (reflect.Value, error)
+	// provided is true if the flag has been provided
+	provided bool
+}
+
+// Set adds the items to the map (the first call replaces the map).
+func (v *mapValue) Set(value string) error {
+	if !v.provided {
+		v.provided = true
+		v.ptr.Elem().Set(reflect.MakeMap(v.ptr.Type().Elem()))
+	}
+	return v.add(value)
+}
+
+// add parses the items and adds them to the map.
+func (v *mapValue) add(value string) error {
+	m := v.ptr.Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	if value == "" {
+		return nil
+	}
+	for _, item := range strings.Split(value, comma) {
+		i := strings.IndexByte(item, '=')
+		if i < 0 {
+			return errInvalidMapItem(item)
+		}
+		elem, err := v.parse(item[i+1:])
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(item[:i]).Convert(m.Type().Key()), elem)
+	}
+	return nil
+}
+
+// String returns comma-separated items.
+func (v *mapValue) String() string {
+	if !v.ptr.IsValid() {
+		return ""
+	}
+	return formatValue(v.ptr.Elem())
+}
+
+// setMap assigns the value to the map field (if provided) and defines the
+// flag.
+func (s *loadState) setMap(field reflect.Value, flagSet *FlagSet, flgKey, value string) error {
+	typ := field.Type()
+	if typ.Key().Kind() != reflect.String {
+		return errUnsupportedType(typ.String())
+	}
+	v := &mapValue{ptr: field.Addr(), parse: func(item string) (reflect.Value, error) {
+		return s.parseValue(typ.Elem(), flgKey, item)
+	}}
+	if value != "" {
+		field.Set(reflect.Zero(typ))
+		if err := v.add(value); err != nil {
+			if e, ok := err.(*ParseError); ok {
+				return e
+			}
+			return &ParseError{Value: value, Type: typ.String(), Err: err}
+		}
+	}
+	flagSet.Var(v, flgKey, "")
+	return nil
+}
+
+// scanEnv collects map items provided with env variables (and dotenv
+// entries) named PREFIX_KEY, where PREFIX is the name of the env variable of
+// the field (keys are lower-cased). The items are added to the value of the
+// env layer (or replace the values of lower layers), every value is used as a
+// whole. The source is the dotenv file unless any env variable is used.
+func (s *loadState) scanEnv(f *fieldInfo) error {
+	prefix := f.Env + s.envNaming.Sep
+	items := make(map[string]string)
+	// files are the dotenv files of the items (env variables are not there)
+	files := make(map[string]string)
+	for key, value := range s.dotEnv {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			item := strings.ToLower(key[len(prefix):])
+			items[item], files[item] = value, s.dotEnvOrigins[key]
+		}
+	}
+	for name, value := range s.scanEnvNames(prefix) {
+		item := strings.ToLower(name[len(prefix):])
+		items[item] = value
+		delete(files, item)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	origin := Field{Source: SourceEnv}
+	if len(files) == len(items) && f.Source != SourceEnv {
+		origin = Field{Source: SourceDotEnv, File: files[keys[0]]}
+	}
+	m := f.rv
+	var value []string
+	if (f.Source == SourceEnv || f.Source == SourceDotEnv) && f.value != "" {
+		value = append(value, f.value)
+		if f.Source == SourceDotEnv && origin.Source == SourceDotEnv {
+			origin.File = f.File
+		}
+	} else {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	for _, key := range keys {
+		elem, err := s.parseValue(m.Type().Elem(), f.Flag, items[key])
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), elem)
+		value = append(value, key+"="+items[key])
+	}
+	f.set(origin, strings.Join(value, comma))
+	return nil
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Loader_Maps(t *testing.T) {
+	type testConfig struct {
+		Labels   map[string]string `default:"env=dev,team=core"`
+		Weights  map[string]float64
+		Timeouts map[string]time.Duration
+		Levels   map[string]testLevel
+		Headers  map[string]string `envscan:"true"`
+	}
+	Convey("Map fields", t, func() {
+		Convey("default value", func() {
+			conf := new(testConfig)
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldBeNil)
+			So(conf.Labels, ShouldResemble, map[string]string{"env": "dev", "team": "core"})
+			So(conf.Weights, ShouldBeNil)
+		})
+		Convey("values from all the layers", func() {
+			path := writeFile(t, "app.yaml", "weights:\n  a: 1\n  b: 0.5\nlevels:\n  db: error\n")
+			conf := new(testConfig)
+			l := NewLoader(
+				WithArgs([]string{"-labels", "env=prod", "-labels", "zone=eu"}),
+				WithLookupEnv(mapEnv(map[string]string{"TIMEOUTS": "read=1s,write=2s"})),
+				WithFile(path),
+			)
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Labels, ShouldResemble, map[string]string{"env": "prod", "zone": "eu"})
+			So(conf.Weights, ShouldResemble, map[string]float64{"a": 1, "b": 0.5})
+			So(conf.Timeouts, ShouldResemble, map[string]time.Duration{"read": time.Second, "write": 2 * time.Second})
+			So(conf.Levels, ShouldResemble, map[string]testLevel{"db": levelError})
+			Convey("dump", func() {
+				var buf bytes.Buffer
+				So(report.Dump(&buf, FormatJSON), ShouldBeNil)
+				So(buf.String(), ShouldContainSubstring, `"read": "1s"`)
+				buf.Reset()
+				So(report.Dump(&buf, FormatTable), ShouldBeNil)
+				So(buf.String(), ShouldContainSubstring, "env=prod,zone=eu")
+			})
+		})
+		Convey("INI section", func() {
+			path := writeFile(t, "app.ini", "[weights]\na = 1\nb = 0.5\n")
+			conf := new(testConfig)
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithFile(path)).Load(conf), ShouldBeNil)
+			So(conf.Weights, ShouldResemble, map[string]float64{"a": 1, "b": 0.5})
+		})
+		Convey("env prefix scan", func() {
+			dotEnv := writeFile(t, ".env", "APP_HEADERS_X_TOKEN=dotenv\nAPP_HEADERS_ACCEPT=text/plain\n")
+			conf := new(testConfig)
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs(nil),
+				WithDotEnvFiles(dotEnv),
+				withEnv(map[string]string{"APP_HEADERS": "host=example.com", "APP_HEADERS_X_TOKEN": "secret", "APP_LABELS_TEAM": "ignored"}),
+			)
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Headers, ShouldResemble, map[string]string{"host": "example.com", "x_token": "secret", "accept": "text/plain"})
+			So(conf.Labels, ShouldResemble, map[string]string{"env": "dev", "team": "core"})
+			origin, _ := report.Provenance("Headers")
+			So(origin.Source, ShouldEqual, SourceEnv)
+		})
+		Convey("env prefix scan of dotenv entries only", func() {
+			dotEnv := writeFile(t, ".env", "APP_HEADERS_ACCEPT=text/plain\n")
+			conf := new(testConfig)
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs(nil),
+				WithDotEnvFiles(dotEnv),
+				WithLookupEnv(mapEnv(nil)),
+			)
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Headers, ShouldResemble, map[string]string{"accept": "text/plain"})
+			origin, _ := report.Provenance("Headers")
+			So(origin.Source, ShouldEqual, SourceDotEnv)
+			So(origin.File, ShouldEqual, dotEnv)
+		})
+		Convey("env prefix scan with commas in values", func() {
+			conf := new(testConfig)
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs(nil),
+				withEnv(map[string]string{"APP_HEADERS_ACCEPT": "text/plain,text/html"}),
+			)
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Headers, ShouldResemble, map[string]string{"accept": "text/plain,text/html"})
+		})
+		Convey("env prefix scan with empty env variable of the field", func() {
+			conf := &struct {
+				Labels map[string]string `default:"env=dev" envscan:"true" allowempty:"true"`
+			}{}
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs(nil),
+				withEnv(map[string]string{"APP_LABELS": "", "APP_LABELS_TEAM": "x"}),
+			)
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Labels, ShouldResemble, map[string]string{"team": "x"})
+		})
+		Convey("env prefix scan replaces lower layers", func() {
+			conf := &struct {
+				Labels map[string]string `default:"env=dev" envscan:"true"`
+			}{}
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs(nil),
+				withEnv(map[string]string{"APP_LABELS_TEAM": "x"}),
+			)
+			So(l.Load(conf), ShouldBeNil)
+			So(conf.Labels, ShouldResemble, map[string]string{"team": "x"})
+		})
+		Convey("invalid values", func() {
+			l := NewLoader(
+				WithArgs([]string{"-weights", "a=x"}),
+				WithLookupEnv(mapEnv(map[string]string{"TIMEOUTS": "read"})),
+			)
+			So(l.Load(new(testConfig)), ShouldResemble, Errors{
+				&ParseError{
+					Field: Field{Path: "Timeouts", Flag: "timeouts", Env: "TIMEOUTS", Source: SourceEnv},
+					Value: "read",
+					Type:  "map[string]time.Duration",
+					Err:   errInvalidMapItem("read"),
+				},
+				&ParseError{
+					Field: Field{Path: "Weights", Flag: "weights", Env: "WEIGHTS", Source: SourceFlag},
+					Value: "x",
+					Type:  "float64",
+				},
+			})
+		})
+		Convey("unsupported key type", func() {
+			conf := &struct{ Codes map[int]string }{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldResemble, Errors{
+				&UnsupportedTypeError{Field: Field{Path: "Codes", Flag: "codes", Env: "CODES"}, Type: "map[int]string"},
+			})
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy defines how Go names of nested structs and the field are
+// converted into the name of the flag, the env variable or the key of config
+// files (every key of the path is converted separately).
+type NamingStrategy struct {
+	// Split splits the Go name into words (nil keeps the name as one word)
+	Split func(name string) []string
+	// Case converts every word (nil keeps the word as is)
+	Case func(word string) string
+	// Sep joins the words and the names of nested structs
+	Sep string
+}
+
+// Predefined naming strategies (the examples are for DB.MaxIdleConns field).
+var (
+	// Lowercase - db-maxidleconns (default for flags and keys of config files)
+	Lowercase = NamingStrategy{Case: strings.ToLower, Sep: "-"}
+	// Uppercase - DB_MAXIDLECONNS (default for env variables)
+	Uppercase = NamingStrategy{Case: strings.ToUpper, Sep: "_"}
+	// KebabCase - db-max-idle-conns
+	KebabCase = NamingStrategy{Split: SplitWords, Case: strings.ToLower, Sep: "-"}
+	// SnakeCase - db_max_idle_conns
+	SnakeCase = NamingStrategy{Split: SplitWords, Case: strings.ToLower, Sep: "_"}
+	// ScreamingSnakeCase - DB_MAX_IDLE_CONNS
+	ScreamingSnakeCase = NamingStrategy{Split: SplitWords, Case: strings.ToUpper, Sep: "_"}
+	// DotCase - db.max.idle.conns
+	DotCase = NamingStrategy{Split: SplitWords, Case: strings.ToLower, Sep: "."}
+	// AsIs - DB_MaxIdleConns
+	AsIs = NamingStrategy{Sep: "_"}
+)
+
+// name converts the names (space-separated names of nested structs are
+// allowed, empty ones are omitted) into a single name.
+func (n NamingStrategy) name(names ...string) string {
+	var words []string
+	for _, name := range names {
+		for _, part := range strings.Fields(name) {
+			if n.Split == nil {
+				words = append(words, part)
+			} else {
+				words = append(words, n.Split(part)...)
+			}
+		}
+	}
+	if n.Case != nil {
+		for i := range words {
+			words[i] = n.Case(words[i])
+		}
+	}
+	return strings.Join(words, n.Sep)
+}
+
+// path converts every name of the path separately (keys of config files).
+func (n NamingStrategy) path(names []string) []string {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = n.name(name)
+	}
+	return keys
+}
+
+// mixedCaseAcronyms are kept as one word by SplitWords.
+var mixedCaseAcronyms = []string{"OAuth", "GraphQL"}
+
+// SplitWords splits the Go name into words at case changes keeping acronyms
+// together (HTTPPort - HTTP, Port), digits stick to the previous word and
+// other characters (e.g. underscores) separate the words. Acronyms with a
+// version suffix and known mixed-case acronyms are kept as one word (IPv6Addr -
+// IPv6, Addr; OAuth2Token - OAuth2, Token).
+func SplitWords(name string) []string {
+	var (
+		words []string
+		runes = []rune(name)
+		start = -1
+		// end of the mixed-case acronym the word starts with
+		keep int
+	)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start, keep = i, i+acronymLen(runes[i:])
+			continue
+		}
+		if i < keep {
+			continue
+		}
+		prev := runes[i-1]
+		// lower (or digit) to upper: maxIdle, v2Config; the last letter of
+		// the acronym starts the next word: HTTPPort (unless it is followed by
+		// the version: IPv6, IDv2)
+		if unicode.IsUpper(r) && (!unicode.IsUpper(prev) || i == keep && keep > start ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !versioned(runes[i+1:])) {
+			words = append(words, string(runes[start:i]))
+			start, keep = i, i+acronymLen(runes[i:])
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// acronymLen returns the length of the mixed-case acronym the runes start with
+// (0 if there is none).
+func acronymLen(runes []rune) int {
+	for _, acronym := range mixedCaseAcronyms {
+		n := len([]rune(acronym))
+		if len(runes) >= n && string(runes[:n]) == acronym && (len(runes) == n || !unicode.IsLower(runes[n])) {
+			return n
+		}
+	}
+	return 0
+}
+
+// versioned checks if the runes start with a version suffix (v2 of IDv2).
+func versioned(runes []rune) bool {
+	return len(runes) > 1 && runes[0] == 'v' && unicode.IsDigit(runes[1])
+}
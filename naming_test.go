@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_SplitWords(t *testing.T) {
+	cases := map[string][]string{
+		"Port":         {"Port"},
+		"HTTPPort":     {"HTTP", "Port"},
+		"MaxIdleConns": {"Max", "Idle", "Conns"},
+		"UserID":       {"User", "ID"},
+		"ID":           {"ID"},
+		"userName":     {"user", "Name"},
+		"V2Config":     {"V2", "Config"},
+		"Port2":        {"Port2"},
+		"snake_case":   {"snake", "case"},
+		"IPv6Addr":     {"IPv6", "Addr"},
+		"OAuth2Token":  {"OAuth2", "Token"},
+		"HTTPServer":   {"HTTP", "Server"},
+		"UserIDv2":     {"User", "IDv2"},
+		"DBHost2":      {"DB", "Host2"},
+		"TLSCert2File": {"TLS", "Cert2", "File"},
+		"GraphQLAPI":   {"GraphQL", "API"},
+		"OAuthToken":   {"OAuth", "Token"},
+		"OAuthor":      {"O", "Author"},
+		"":             nil,
+	}
+	Convey("Split Go names into words", t, func() {
+		for name, words := range cases {
+			So(SplitWords(name), ShouldResemble, words)
+		}
+	})
+}
+
+func Test_NamingStrategy(t *testing.T) {
+	cases := []struct {
+		title  string
+		naming NamingStrategy
+		out    string
+	}{
+		{"lowercase", Lowercase, "db-httpport"},
+		{"uppercase", Uppercase, "DB_HTTPPORT"},
+		{"kebab-case", KebabCase, "db-http-port"},
+		{"snake_case", SnakeCase, "db_http_port"},
+		{"SCREAMING_SNAKE", ScreamingSnakeCase, "DB_HTTP_PORT"},
+		{"dot.case", DotCase, "db.http.port"},
+		{"as-is", AsIs, "DB_HTTPPort"},
+	}
+	Convey("Naming strategies", t, func() {
+		for _, c := range cases {
+			Convey(c.title, func() {
+				So(c.naming.name("", "DB", "HTTPPort"), ShouldEqual, c.out)
+			})
+		}
+	})
+}
+
+func Test_Loader_Naming(t *testing.T) {
+	type backend struct {
+		HostName string
+	}
+	type testConfig struct {
+		HTTPPort int
+		DB       struct {
+			MaxIdleConns int
+		}
+		Backends []backend
+		Labels   map[string]string `envscan:"true"`
+	}
+	Convey("Naming strategies of the loader", t, func() {
+		Convey("flags, env variables and keys", func() {
+			path := filepath.Join(t.TempDir(), "config.json")
+			So(os.WriteFile(path, []byte(`{"db": {"max_idle_conns": 5}, "backends": [{"host_name": "a.local"}]}`), 0o600), ShouldBeNil)
+			conf := new(testConfig)
+			report, err := NewLoader(
+				WithEnvPrefix("MyApp"),
+				WithArgs([]string{"--http-port", "8080", "--backends-1-host-name", "b.local"}),
+				withEnv(map[string]string{"MY_APP_BACKENDS_2_HOST_NAME": "c.local", "MY_APP_LABELS_TEAM": "core"}),
+				WithJSONFile(path),
+				WithFlagNaming(KebabCase),
+				WithEnvNaming(ScreamingSnakeCase),
+				WithKeyNaming(SnakeCase),
+			).LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.HTTPPort, ShouldEqual, 8080)
+			So(conf.DB.MaxIdleConns, ShouldEqual, 5)
+			So(conf.Backends, ShouldResemble, []backend{{"a.local"}, {"b.local"}, {"c.local"}})
+			So(conf.Labels, ShouldResemble, map[string]string{"team": "core"})
+			origin, ok := report.Provenance("DB.MaxIdleConns")
+			So(ok, ShouldBeTrue)
+			So(origin.Flag, ShouldEqual, "db-max-idle-conns")
+			So(origin.Env, ShouldEqual, "MY_APP_DB_MAX_IDLE_CONNS")
+			So(origin.Key, ShouldEqual, "db.max_idle_conns")
+		})
+		Convey("keys that do not match the strategy are unknown", func() {
+			path := filepath.Join(t.TempDir(), "config.json")
+			So(os.WriteFile(path, []byte(`{"httpport": 1}`), 0o600), ShouldBeNil)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithJSONFile(path), WithKeyNaming(SnakeCase)).Load(new(testConfig))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "unknown key [httpport]")
+		})
+		Convey("legacy names by default", func() {
+			conf := new(testConfig)
+			err := NewLoader(
+				WithArgs([]string{"--httpport", "1"}),
+				WithLookupEnv(mapEnv(map[string]string{"DB_MAXIDLECONNS": "2"})),
+			).Load(conf)
+			So(err, ShouldBeNil)
+			So(conf.HTTPPort, ShouldEqual, 1)
+			So(conf.DB.MaxIdleConns, ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ptrValue is a flag.Value of the pointer field, the value is allocated when
+// it is provided (the field is left nil otherwise).
+type ptrValue struct {
+	ptr   reflect.Value
+	parse func(string) (reflect.Value, error)
+}
+
+// Set parses the value and allocates the pointer.
+func (v *ptrValue) Set(value string) error {
+	elem, err := v.parse(value)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(elem.Type())
+	ptr.Elem().Set(elem)
+	v.ptr.Elem().Set(ptr)
+	return nil
+}
+
+// String formats the value (empty if the pointer is nil).
+func (v *ptrValue) String() string {
+	if !v.ptr.IsValid() {
+		return ""
+	}
+	return formatValue(v.ptr.Elem())
+}
+
+// IsBoolFlag allows to use the flag without the value if it points to bool.
+func (v *ptrValue) IsBoolFlag() bool {
+	return v.ptr.IsValid() && v.ptr.Type().Elem().Elem().Kind() == reflect.Bool
+}
+
+// isPointer checks if the type is a pointer leaf (not a pointer to nested
+// struct section).
+func (s *loadState) isPointer(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Ptr && s.textType(typ) == nil && !s.isSection(typ)
+}
+
+// isSection checks if the type is a pointer to nested struct section.
+func (s *loadState) isSection(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct &&
+		s.textType(typ) == nil && s.textType(typ.Elem()) == nil
+}
+
+// setPointer assigns the provided value to the pointer field (allocates it)
+// and defines the flag, the field is left as is otherwise.
+func (s *loadState) setPointer(f *fieldInfo, provided bool) error {
+	v := &ptrValue{ptr: f.rv.Addr(), parse: func(value string) (reflect.Value, error) {
+		return s.parseValue(f.typ.Elem(), f.Flag, value)
+	}}
+	if provided {
+		if err := v.Set(f.value); err != nil {
+			return err
+		}
+	} else if _, err := v.parse(""); err != nil {
+		// check if the type is supported
+		return err
+	}
+	s.flagSet.Var(v, f.Flag, "")
+	return nil
+}
+
+// section is a pointer to nested struct that is allocated on demand.
+type section struct {
+	// path is a Go path of the field
+	path string
+	// field is the pointer field and value is a pointer to the loaded struct
+	field, value reflect.Value
+}
+
+// initSection loads the pointer to nested struct section. The section is
+// loaded into a new struct that is assigned to the field only if any value
+// of the section is provided (see finishSections), an already allocated
+// section is loaded as is.
+func (s *loadState) initSection(field reflect.Value, prefix, goPath string) {
+	if !field.IsNil() {
+		s.initConfig(field, prefix, goPath)
+		return
+	}
+	value := reflect.New(field.Type().Elem())
+	setDefaults(value)
+	s.initConfig(value, prefix, goPath)
+	s.sections = append(s.sections, section{path: goPath, field: field, value: value})
+}
+
+// finishSections assigns the sections with provided values (except defaults),
+// the fields and structs of the other sections are not checked any further.
+func (s *loadState) finishSections() {
+	var unset []string
+	for _, sec := range s.sections {
+		provided := false
+		for _, f := range s.fields {
+			if strings.HasPrefix(f.Path, sec.path+".") && f.provided() {
+				provided = true
+				break
+			}
+		}
+		if provided {
+			sec.field.Set(sec.value)
+		} else {
+			unset = append(unset, sec.path)
+		}
+	}
+	if len(unset) == 0 {
+		return
+	}
+	inUnset := func(path string) bool {
+		for _, prefix := range unset {
+			if path == prefix || strings.HasPrefix(path, prefix+".") {
+				return true
+			}
+		}
+		return false
+	}
+	fields := s.fields[:0]
+	for _, f := range s.fields {
+		if !inUnset(f.Path) {
+			fields = append(fields, f)
+		}
+	}
+	s.fields = fields
+	structs := s.structs[:0]
+	for _, st := range s.structs {
+		if !inUnset(st.path) {
+			structs = append(structs, st)
+		}
+	}
+	s.structs = structs
+}
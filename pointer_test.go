@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Loader_Pointers(t *testing.T) {
+	type database struct {
+		Host string `default:"localhost"`
+		Port int    `default:"5432" max:"65535"`
+		User string `required:"true"`
+	}
+	type testConfig struct {
+		Timeout *time.Duration `min:"1s"`
+		Retries *int
+		Debug   *bool
+		Name    *string `default:"app"`
+		Level   *testLevel
+		Tags    *[]string
+		DB      *database
+		Cache   *hooksTLS
+	}
+	Convey("Pointer fields", t, func() {
+		Convey("not configured", func() {
+			conf := new(testConfig)
+			report, err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Timeout, ShouldBeNil)
+			So(conf.Retries, ShouldBeNil)
+			So(conf.Debug, ShouldBeNil)
+			So(*conf.Name, ShouldEqual, "app")
+			So(conf.Level, ShouldBeNil)
+			So(conf.Tags, ShouldBeNil)
+			So(conf.DB, ShouldBeNil)
+			So(conf.Cache, ShouldBeNil)
+			_, ok := report.Provenance("DB.Host")
+			So(ok, ShouldBeFalse)
+			Convey("dump", func() {
+				var buf bytes.Buffer
+				So(report.Dump(&buf, FormatJSON), ShouldBeNil)
+				So(buf.String(), ShouldContainSubstring, `"value": null`)
+			})
+		})
+		Convey("configured by the layers", func() {
+			path := writeFile(t, "app.yaml", "retries: 0\ndb:\n  user: admin\n")
+			conf := new(testConfig)
+			l := NewLoader(
+				WithArgs([]string{"-debug", "-cache-port", "6379"}),
+				WithLookupEnv(mapEnv(map[string]string{"TIMEOUT": "5s", "LEVEL": "error", "TAGS": "a,b"})),
+				WithFile(path),
+			)
+			So(l.Load(conf), ShouldBeNil)
+			So(*conf.Timeout, ShouldEqual, 5*time.Second)
+			So(*conf.Retries, ShouldEqual, 0)
+			So(*conf.Debug, ShouldBeTrue)
+			So(*conf.Level, ShouldEqual, levelError)
+			So(*conf.Tags, ShouldResemble, []string{"a", "b"})
+			So(*conf.DB, ShouldResemble, database{Host: "localhost", Port: 5432, User: "admin"})
+			So(*conf.Cache, ShouldResemble, hooksTLS{Port: 6379})
+		})
+		Convey("values set before loading", func() {
+			retries := 3
+			conf := &testConfig{Retries: &retries, DB: &database{User: "root"}}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldBeNil)
+			So(*conf.Retries, ShouldEqual, 3)
+			So(*conf.DB, ShouldResemble, database{Host: "localhost", Port: 5432, User: "root"})
+		})
+		Convey("errors", func() {
+			l := NewLoader(
+				WithArgs([]string{"-db-port", "70000", "-cache-enabled"}),
+				WithLookupEnv(mapEnv(map[string]string{"TIMEOUT": "10ms"})),
+			)
+			So(l.Load(new(testConfig)), ShouldResemble, Errors{
+				errMissingRequired(Field{Path: "DB.User", Flag: "db-user", Env: "DB_USER"}),
+				&ValidationError{Field: Field{Path: "Timeout", Flag: "timeout", Env: "TIMEOUT", Source: SourceEnv}, Rule: keyMinTag, Param: "1s", Value: "10ms"},
+				&ValidationError{Field: Field{Path: "DB.Port", Flag: "db-port", Env: "DB_PORT", Source: SourceFlag}, Rule: keyMaxTag, Param: "65535", Value: "70000"},
+				&FieldError{Field: Field{Path: "Cache"}, Err: errCertRequired},
+			})
+		})
+		Convey("unsupported type", func() {
+			conf := &struct{ Value *complex64 }{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldResemble, Errors{
+				&UnsupportedTypeError{Field: Field{Path: "Value", Flag: "value", Env: "VALUE"}, Type: "complex64"},
+			})
+		})
+	})
+}
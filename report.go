@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// secretMask replaces the values of secret fields
+const secretMask = "******"
+
+// Origin describes the value of the config field and the source that supplied
+// it (the one that won according to the priorities).
+type Origin struct {
+	Field
+	// Value is a raw value (empty if the value was not provided)
+	Value string
+	// Secret is true if the field is tagged as secret (the value is masked
+	// when the origin is printed)
+	Secret bool
+	// current is the field value
+	current reflect.Value
+	// text is the type parsed from text (the type of the field or its items)
+	text reflect.Type
+}
+
+// String returns the field path, the value and where it came from.
+func (o Origin) String() string {
+	if o.Source == "" {
+		return fmt.Sprintf("%s: not set", o.Path)
+	}
+	value := o.Value
+	if o.Secret {
+		value = secretMask
+	}
+	return fmt.Sprintf("%s = %q (%s)", o.Path, value, o.origin())
+}
+
+// Report describes where the values of config fields came from.
+type Report struct {
+	// origins of all the config fields (in order they are declared)
+	origins []Origin
+	// index contains positions of the origins by field path
+	index map[string]int
+}
+
+// newReport creates the report from the visited config fields.
+func newReport(fields []*fieldInfo) *Report {
+	r := &Report{origins: make([]Origin, len(fields)), index: make(map[string]int, len(fields))}
+	for i, f := range fields {
+		r.origins[i] = Origin{Field: f.Field, Value: f.value, Secret: f.secret, current: f.rv, text: f.text}
+		r.index[f.Path] = i
+	}
+	return r
+}
+
+// Provenance returns the origin of the value of the config field by its Go
+// path (e.g. "Database.Port").
+func (r *Report) Provenance(path string) (Origin, bool) {
+	i, ok := r.index[path]
+	if !ok {
+		return Origin{}, false
+	}
+	return r.origins[i], true
+}
+
+// Origins returns the origins of all the config fields (in order they are
+// declared).
+func (r *Report) Origins() []Origin {
+	return append([]Origin(nil), r.origins...)
+}
+
+// String returns the full report (one field per line).
+func (r *Report) String() string {
+	lines := make([]string, len(r.origins))
+	for i, origin := range r.origins {
+		lines[i] = origin.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Report(t *testing.T) {
+	type testConfig struct {
+		Name    string `default:"default"`
+		Port    int    `default:"80"`
+		Host    string `default:"localhost"`
+		User    string
+		Debug   bool
+		Timeout string
+		Missing string
+	}
+	Convey("Report", t, func() {
+		file := writeFile(t, "config.yaml", "port: 8080\nhost: example.com\n")
+		dotEnv := writeFile(t, ".env", "APP_HOST=dotenv.com\nAPP_TIMEOUT=5s\n")
+		l := NewLoader(
+			WithEnvPrefix("APP"),
+			WithArgs([]string{"-debug", "-host", "flag.com"}),
+			WithFile(file),
+			WithDotEnvFiles(dotEnv),
+			WithLookupEnv(mapEnv(map[string]string{"APP_USER": "admin"})),
+		)
+		report, err := l.LoadReport(new(testConfig))
+		So(err, ShouldBeNil)
+
+		Convey("provenance of every field", func() {
+			type testCase struct {
+				path   string
+				origin Origin
+			}
+			var cases = []testCase{
+				{"Name", Origin{Field: Field{Path: "Name", Flag: "name", Env: "APP_NAME", Source: SourceDefault}, Value: "default"}},
+				{"Port", Origin{Field: Field{Path: "Port", Flag: "port", Env: "APP_PORT", Source: SourceFile, File: file, Key: "port"}, Value: "8080"}},
+				{"Host", Origin{Field: Field{Path: "Host", Flag: "host", Env: "APP_HOST", Source: SourceFlag}, Value: "flag.com"}},
+				{"User", Origin{Field: Field{Path: "User", Flag: "user", Env: "APP_USER", Source: SourceEnv}, Value: "admin"}},
+				{"Debug", Origin{Field: Field{Path: "Debug", Flag: "debug", Env: "APP_DEBUG", Source: SourceFlag}, Value: "true"}},
+				{"Timeout", Origin{Field: Field{Path: "Timeout", Flag: "timeout", Env: "APP_TIMEOUT", Source: SourceDotEnv, File: dotEnv}, Value: "5s"}},
+				{"Missing", Origin{Field: Field{Path: "Missing", Flag: "missing", Env: "APP_MISSING"}, Value: ""}},
+			}
+			for _, c := range cases {
+				origin, ok := report.Provenance(c.path)
+				So(ok, ShouldBeTrue)
+				So(origin.Field, ShouldResemble, c.origin.Field)
+				So(origin.Value, ShouldEqual, c.origin.Value)
+				So(origin.Secret, ShouldBeFalse)
+			}
+			So(report.Origins(), ShouldHaveLength, len(cases))
+		})
+
+		Convey("unknown field", func() {
+			_, ok := report.Provenance("Unknown")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("full report", func() {
+			So(report.String(), ShouldEqual, `Name = "default" (default value)
+Port = "8080" (file `+file+`, key [port])
+Host = "flag.com" (flag --host)
+User = "admin" (env APP_USER)
+Debug = "true" (flag --debug)
+Timeout = "5s" (env APP_TIMEOUT from `+dotEnv+`)
+Missing: not set`)
+		})
+	})
+
+	Convey("Report is returned even if loading has failed", t, func() {
+		l := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(map[string]string{"PORT": "x"})))
+		report, err := l.LoadReport(&struct{ Port int }{})
+		So(err, ShouldNotBeNil)
+		origin, ok := report.Provenance("Port")
+		So(ok, ShouldBeTrue)
+		So(origin.Source, ShouldEqual, SourceEnv)
+		So(origin.Value, ShouldEqual, "x")
+	})
+
+	Convey("Report is not returned for invalid receiver", t, func() {
+		report, err := NewLoader().LoadReport(new(int))
+		So(err, ShouldEqual, errInvalidReceiver)
+		So(report, ShouldBeNil)
+	})
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+)
+
+// checkRequirements checks conditional (required_if, required_with) and group
+// (mutually_exclusive) requirements of the config fields (that have no errors
+// yet). Rules refer to the fields of the same struct by name or to any other
+// field by Go path.
+func (s *loadState) checkRequirements() {
+	index := make(map[string]*fieldInfo, len(s.fields))
+	for _, f := range s.fields {
+		index[f.Path] = f
+	}
+	// find the field the rule refers to
+	lookup := func(f *fieldInfo, name string) (*fieldInfo, bool) {
+		if other, ok := index[joinStrings(".", parentPath(f.Path), name)]; ok {
+			return other, true
+		}
+		other, ok := index[name]
+		return other, ok
+	}
+	// first provided field of every mutually exclusive group
+	groups := make(map[string]*fieldInfo)
+	for _, f := range s.fields {
+		if f.failed {
+			continue
+		}
+		if rule, ok := f.tag.Lookup(keyRequiredIfTag); ok {
+			name, value := splitRule(rule)
+			if other, ok := lookup(f, name); !ok {
+				s.fail(f, errUnknownField(keyRequiredIfTag, name))
+			} else if f.Source == "" && formatValue(other.rv) == value {
+				f.failed = true
+				s.errs = append(s.errs, &RequirementError{Field: f.Field, Rule: keyRequiredIfTag, Other: other.Field, Value: value})
+			}
+		}
+		if rule, ok := f.tag.Lookup(keyRequiredWithTag); ok && f.Source == "" {
+			for _, name := range strings.Fields(rule) {
+				other, ok := lookup(f, name)
+				if !ok {
+					s.fail(f, errUnknownField(keyRequiredWithTag, name))
+					break
+				}
+				if other.provided() {
+					f.failed = true
+					s.errs = append(s.errs, &RequirementError{Field: f.Field, Rule: keyRequiredWithTag, Other: other.Field})
+					break
+				}
+			}
+		}
+		if group, ok := f.tag.Lookup(keyExclusiveTag); ok && f.provided() {
+			key := parentPath(f.Path) + space + group
+			if other, ok := groups[key]; ok {
+				f.failed = true
+				s.errs = append(s.errs, &RequirementError{Field: f.Field, Rule: keyExclusiveTag, Other: other.Field})
+			} else {
+				groups[key] = f
+			}
+		}
+	}
+}
+
+// provided checks if the value has been provided by any source except the
+// default value.
+func (f *fieldInfo) provided() bool {
+	return f.Source != "" && f.Source != SourceDefault
+}
+
+// parentPath returns the path of the struct that contains the field.
+func parentPath(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// splitRule splits "Field value" rule into field name and value.
+func splitRule(rule string) (name, value string) {
+	rule = strings.TrimSpace(rule)
+	if i := strings.IndexByte(rule, ' '); i >= 0 {
+		return rule[:i], strings.TrimSpace(rule[i+1:])
+	}
+	return rule, ""
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Loader_Requirements(t *testing.T) {
+	type testConfig struct {
+		TLS struct {
+			Enabled bool
+			Cert    string `required_if:"Enabled true"`
+			Key     string `required_with:"Cert"`
+		}
+		Password     string `mutually_exclusive:"password"`
+		PasswordFile string `mutually_exclusive:"password"`
+		Mode         string `default:"dev"`
+		Debug        bool   `required_if:"Mode dev"`
+	}
+	tlsEnabled := Field{Path: "TLS.Enabled", Flag: "tls-enabled", Env: "TLS_ENABLED", Source: SourceFlag}
+	tlsCert := Field{Path: "TLS.Cert", Flag: "tls-cert", Env: "TLS_CERT"}
+	tlsKey := Field{Path: "TLS.Key", Flag: "tls-key", Env: "TLS_KEY"}
+	Convey("Conditional and group requirements", t, func() {
+		Convey("satisfied", func() {
+			l := NewLoader(
+				WithArgs([]string{"-tls-enabled", "-tls-cert", "cert.pem", "-tls-key", "key.pem", "-debug"}),
+				WithLookupEnv(mapEnv(map[string]string{"PASSWORD": "secret"})),
+			)
+			So(l.Load(new(testConfig)), ShouldBeNil)
+		})
+		Convey("not satisfied", func() {
+			l := NewLoader(
+				WithArgs([]string{"-tls-enabled", "-passwordfile", "/run/secret"}),
+				WithLookupEnv(mapEnv(map[string]string{"PASSWORD": "secret"})),
+			)
+			err := l.Load(new(testConfig))
+			So(err, ShouldResemble, Errors{
+				&RequirementError{Field: tlsCert, Rule: keyRequiredIfTag, Other: tlsEnabled, Value: "true"},
+				&RequirementError{
+					Field: Field{Path: "PasswordFile", Flag: "passwordfile", Env: "PASSWORDFILE", Source: SourceFlag},
+					Rule:  keyExclusiveTag,
+					Other: Field{Path: "Password", Flag: "password", Env: "PASSWORD", Source: SourceEnv},
+				},
+				&RequirementError{
+					Field: Field{Path: "Debug", Flag: "debug", Env: "DEBUG"},
+					Rule:  keyRequiredIfTag,
+					Other: Field{Path: "Mode", Flag: "mode", Env: "MODE", Source: SourceDefault},
+					Value: "dev",
+				},
+			})
+		})
+		Convey("required with", func() {
+			l := NewLoader(WithArgs([]string{"-tls-cert", "cert.pem", "-debug"}), WithLookupEnv(mapEnv(nil)))
+			So(l.Load(new(testConfig)), ShouldResemble, Errors{
+				&RequirementError{Field: tlsKey, Rule: keyRequiredWithTag, Other: Field{Path: "TLS.Cert", Flag: "tls-cert", Env: "TLS_CERT", Source: SourceFlag}},
+			})
+		})
+		Convey("unknown field", func() {
+			conf := &struct {
+				Key string `required_with:"Cert"`
+			}{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldResemble, Errors{
+				&FieldError{Field: Field{Path: "Key", Flag: "key", Env: "KEY"}, Err: errUnknownField(keyRequiredWithTag, "Cert")},
+			})
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// isStructSlice checks if the type is a slice of nested structs.
+func isStructSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Struct
+}
+
+// initSlice loads the elements of the slice of nested structs. The number of
+// elements is defined by config files (arrays), indexed env variables
+// (PREFIX_0_NAME) and flags (--prefix-0-name), existing elements are kept and
+// the new ones get the defaults of the element struct. Indexes of env variables
+// and flags can not skip elements.
+func (s *loadState) initSlice(field reflect.Value, prefix, goPath string) {
+	path := strings.Fields(prefix)
+	n := field.Len()
+	for _, file := range s.files {
+		length, err := file.length(path)
+		if err != nil {
+			s.errs = append(s.errs, err)
+		}
+		n = maxInt(n, length)
+	}
+	// names of indexed env variables, dotenv entries and flags by index
+	indexes := make(map[int][]string)
+	envSep := s.envNaming.Sep
+	envPrefix := s.envNaming.name(s.envPrefix, prefix) + envSep
+	for key := range s.dotEnv {
+		if i := indexOf(key, envPrefix, envSep); i >= 0 {
+			indexes[i] = append(indexes[i], key)
+		}
+	}
+	for name := range s.scanEnvNames(envPrefix) {
+		if i := indexOf(name, envPrefix, envSep); i >= 0 {
+			indexes[i] = append(indexes[i], name)
+		}
+	}
+	flagSep := s.flagNaming.Sep
+	flagPrefix := s.flagNaming.name(prefix) + flagSep
+	for _, arg := range s.args {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+			if i := indexOf(name, flagPrefix, flagSep); i >= 0 {
+				indexes[i] = append(indexes[i], "--"+name)
+			}
+		}
+	}
+	// elements are added one by one (e.g. a single PREFIX_1000_NAME variable
+	// does not create 1001 elements)
+	for len(indexes[n]) != 0 {
+		n++
+	}
+	var gaps []string
+	for i, names := range indexes {
+		if i > n {
+			gaps = append(gaps, names...)
+		}
+	}
+	sort.Strings(gaps)
+	for _, name := range gaps {
+		s.errs = append(s.errs, &FieldError{Field: Field{Path: goPath}, Err: errSliceGap(name, n)})
+	}
+	if n > field.Len() {
+		slice := reflect.MakeSlice(field.Type(), n, n)
+		reflect.Copy(slice, field)
+		for i := field.Len(); i < n; i++ {
+			setDefaults(slice.Index(i).Addr())
+		}
+		field.Set(slice)
+	}
+	for i := 0; i < n; i++ {
+		s.initConfig(field.Index(i).Addr(), nestedPrefix(prefix, strconv.Itoa(i)), joinPath(goPath, strconv.Itoa(i)))
+	}
+}
+
+// indexOf parses the index in the name like PREFIX<index><sep>NAME, returns -1
+// if the name does not match.
+func indexOf(name, prefix, sep string) int {
+	if !strings.HasPrefix(name, prefix) {
+		return -1
+	}
+	name = name[len(prefix):]
+	end := strings.Index(name, sep)
+	if end <= 0 {
+		return -1
+	}
+	index, err := strconv.Atoi(name[:end])
+	if err != nil || index < 0 {
+		return -1
+	}
+	return index
+}
+
+// maxInt returns the larger of two integers.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Loader_StructSlices(t *testing.T) {
+	type backend struct {
+		Host   string `default:"localhost"`
+		Port   int    `default:"80"`
+		Weight float64
+	}
+	type testConfig struct {
+		Backends  []backend
+		Listeners []hooksTLS
+	}
+	Convey("Slices of nested structs", t, func() {
+		Convey("elements from all the layers", func() {
+			path := writeFile(t, "app.json", `{"backends": [{"host": "a.local", "weight": 0.5}, {"port": 8080}]}`)
+			conf := new(testConfig)
+			l := NewLoader(
+				WithEnvPrefix("APP"),
+				WithArgs([]string{"--backends-0-port=81", "-listeners-0-enabled=false", "-listeners-1-port", "9443"}),
+				withEnv(map[string]string{"APP_BACKENDS_1_HOST": "b.local", "APP_BACKENDS_2_HOST": "c.local"}),
+				WithFile(path),
+			)
+			report, err := l.LoadReport(conf)
+			So(err, ShouldBeNil)
+			So(conf.Backends, ShouldResemble, []backend{
+				{Host: "a.local", Port: 81, Weight: 0.5},
+				{Host: "b.local", Port: 8080},
+				{Host: "c.local", Port: 80},
+			})
+			So(conf.Listeners, ShouldResemble, []hooksTLS{{Port: 443}, {Port: 9443}})
+			origin, ok := report.Provenance("Backends.1.Port")
+			So(ok, ShouldBeTrue)
+			So(origin.Field, ShouldResemble, Field{
+				Path:   "Backends.1.Port",
+				Flag:   "backends-1-port",
+				Env:    "APP_BACKENDS_1_PORT",
+				Source: SourceFile,
+				File:   path,
+				Key:    "backends.1.port",
+			})
+		})
+		Convey("dotenv entries", func() {
+			path := writeFile(t, ".env", "BACKENDS_0_PORT=80\nBACKENDS_1_HOST=dotenv.local\n")
+			conf := new(testConfig)
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithDotEnvFiles(path)).Load(conf), ShouldBeNil)
+			So(conf.Backends, ShouldResemble, []backend{{Host: "localhost", Port: 80}, {Host: "dotenv.local", Port: 80}})
+		})
+		Convey("existing elements are kept", func() {
+			conf := &testConfig{Backends: []backend{{Weight: 0.5}}}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldBeNil)
+			So(conf.Backends, ShouldResemble, []backend{{Host: "localhost", Port: 80, Weight: 0.5}})
+		})
+		Convey("hooks of the elements", func() {
+			err := NewLoader(WithArgs([]string{"-listeners-0-enabled"}), WithLookupEnv(mapEnv(nil))).Load(new(testConfig))
+			So(err, ShouldResemble, Errors{&FieldError{Field: Field{Path: "Listeners.0"}, Err: errCertRequired}})
+		})
+		Convey("indexes can not skip elements", func() {
+			path := writeFile(t, "app.json", `{"backends": [{"host": "a.local"}]}`)
+			conf := new(testConfig)
+			err := NewLoader(
+				WithArgs([]string{"--backends-1-port=81", "--listeners-1-port=1"}),
+				withEnv(map[string]string{"BACKENDS_200000_HOST": "x", "BACKENDS_2_HOST": "c.local"}),
+				WithFile(path),
+				WithOutput(new(bytes.Buffer)),
+			).Load(conf)
+			So(err, ShouldNotBeNil)
+			So(conf.Backends, ShouldHaveLength, 3)
+			So(conf.Listeners, ShouldBeEmpty)
+			So(err.Error(), ShouldContainSubstring, "field [Backends]: [BACKENDS_200000_HOST] skips slice elements, the next index is [3]")
+			So(err.Error(), ShouldContainSubstring, "field [Listeners]: [--listeners-1-port] skips slice elements, the next index is [0]")
+		})
+		Convey("listed env variables are used only if resolved", func() {
+			conf := new(testConfig)
+			err := NewLoader(
+				WithArgs(nil),
+				WithLookupEnv(mapEnv(map[string]string{"BACKENDS_0_HOST": "a.local"})),
+				WithEnviron(func() []string { return []string{"BACKENDS_0_HOST=stale", "BACKENDS_1_HOST=phantom"} }),
+			).Load(conf)
+			So(err, ShouldBeNil)
+			So(conf.Backends, ShouldResemble, []backend{{Host: "a.local", Port: 80}})
+		})
+		Convey("file errors", func() {
+			path := writeFile(t, "app.json", `{"backends": [{"host": "a.local", "unknown": 1}, "b.local"], "listeners": {"port": 1}}`)
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil)), WithFile(path)).Load(new(testConfig))
+			So(err, ShouldResemble, Errors{
+				errTypeMismatch(Field{Path: "Backends.1", Source: SourceFile, File: path, Key: "backends.1"}, "b.local", "object"),
+				errTypeMismatch(Field{Path: "Listeners", Source: SourceFile, File: path, Key: "listeners"}, map[string]interface{}{"port": json.Number("1")}, "array"),
+				errUnknownKey(path, "backends.0.unknown"),
+			})
+		})
+	})
+}
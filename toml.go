@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlErrorPrefix matches "toml: line N (last key ...): " error message prefix.
+var tomlErrorPrefix = regexp.MustCompile(`^toml: line \d+( \(last key ".*?"\))?: `)
+
+// parseTOML decodes TOML config file, decode errors contain line and column of
+// the invalid input.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	var root map[string]interface{}
+	if _, err := toml.Decode(string(data), &root); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			pos, msg := parseErr.Position, parseErr.Message
+			if msg == "" {
+				msg = tomlErrorPrefix.ReplaceAllString(parseErr.Error(), "")
+			}
+			// the position is not reliable at EOF (the line is not set and the
+			// offset points at the last byte), so both are computed from the
+			// offset of the end of the input
+			offset := minInt(pos.Start, len(data))
+			if strings.HasPrefix(msg, "unexpected EOF") {
+				offset = len(data)
+			}
+			start := string(data[:offset])
+			line := strings.Count(start, "\n") + 1
+			column := len(start) - strings.LastIndexByte(start, '\n')
+			return nil, fmt.Errorf("line %d, column %d: %s", line, column, msg)
+		}
+		return nil, err
+	}
+	return normalizeTOML(root).(map[string]interface{}), nil
+}
+
+// minInt returns the smaller of two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// normalizeTOML recursively converts arrays of tables into generic arrays (so
+// they can be handled like any other tree).
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeTOML(item)
+		}
+		return v
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = normalizeTOML(item)
+		}
+		return list
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
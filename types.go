@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -240,3 +241,461 @@ func (s *arrayString) Get() interface{} {
 func (s *arrayString) String() string {
 	return strings.Join([]string(*s), comma)
 }
+
+// int8Value implements flag.Value, flag.Getter interfaces.
+type int8Value int8
+
+func newInt8Value(val int8, p *int8) *int8Value {
+	*p = val
+	return (*int8Value)(p)
+}
+
+func (i *int8Value) Set(val string) error {
+	v, err := parseInt(val, 8, int8(0))
+	if err != nil {
+		return err
+	}
+	*i = int8Value(v)
+	return nil
+}
+
+func (i *int8Value) Get() interface{} {
+	return int8(*i)
+}
+
+func (i *int8Value) String() string {
+	return strconv.FormatInt(int64(*i), 10)
+}
+
+// int16Value implements flag.Value, flag.Getter interfaces.
+type int16Value int16
+
+func newInt16Value(val int16, p *int16) *int16Value {
+	*p = val
+	return (*int16Value)(p)
+}
+
+func (i *int16Value) Set(val string) error {
+	v, err := parseInt(val, 16, int16(0))
+	if err != nil {
+		return err
+	}
+	*i = int16Value(v)
+	return nil
+}
+
+func (i *int16Value) Get() interface{} {
+	return int16(*i)
+}
+
+func (i *int16Value) String() string {
+	return strconv.FormatInt(int64(*i), 10)
+}
+
+// int32Value implements flag.Value, flag.Getter interfaces.
+type int32Value int32
+
+func newInt32Value(val int32, p *int32) *int32Value {
+	*p = val
+	return (*int32Value)(p)
+}
+
+func (i *int32Value) Set(val string) error {
+	v, err := parseInt(val, 32, int32(0))
+	if err != nil {
+		return err
+	}
+	*i = int32Value(v)
+	return nil
+}
+
+func (i *int32Value) Get() interface{} {
+	return int32(*i)
+}
+
+func (i *int32Value) String() string {
+	return strconv.FormatInt(int64(*i), 10)
+}
+
+// uint8Value implements flag.Value, flag.Getter interfaces.
+type uint8Value uint8
+
+func newUint8Value(val uint8, p *uint8) *uint8Value {
+	*p = val
+	return (*uint8Value)(p)
+}
+
+func (u *uint8Value) Set(val string) error {
+	v, err := parseUint(val, 8, uint8(0))
+	if err != nil {
+		return err
+	}
+	*u = uint8Value(v)
+	return nil
+}
+
+func (u *uint8Value) Get() interface{} {
+	return uint8(*u)
+}
+
+func (u *uint8Value) String() string {
+	return strconv.FormatUint(uint64(*u), 10)
+}
+
+// uint16Value implements flag.Value, flag.Getter interfaces.
+type uint16Value uint16
+
+func newUint16Value(val uint16, p *uint16) *uint16Value {
+	*p = val
+	return (*uint16Value)(p)
+}
+
+func (u *uint16Value) Set(val string) error {
+	v, err := parseUint(val, 16, uint16(0))
+	if err != nil {
+		return err
+	}
+	*u = uint16Value(v)
+	return nil
+}
+
+func (u *uint16Value) Get() interface{} {
+	return uint16(*u)
+}
+
+func (u *uint16Value) String() string {
+	return strconv.FormatUint(uint64(*u), 10)
+}
+
+// uint32Value implements flag.Value, flag.Getter interfaces.
+type uint32Value uint32
+
+func newUint32Value(val uint32, p *uint32) *uint32Value {
+	*p = val
+	return (*uint32Value)(p)
+}
+
+func (u *uint32Value) Set(val string) error {
+	v, err := parseUint(val, 32, uint32(0))
+	if err != nil {
+		return err
+	}
+	*u = uint32Value(v)
+	return nil
+}
+
+func (u *uint32Value) Get() interface{} {
+	return uint32(*u)
+}
+
+func (u *uint32Value) String() string {
+	return strconv.FormatUint(uint64(*u), 10)
+}
+
+// float32Value implements flag.Value, flag.Getter interfaces.
+type float32Value float32
+
+func newFloat32Value(val float32, p *float32) *float32Value {
+	*p = val
+	return (*float32Value)(p)
+}
+
+func (f *float32Value) Set(val string) error {
+	v, err := parseFloat(val, 32, float32(0))
+	if err != nil {
+		return err
+	}
+	*f = float32Value(v)
+	return nil
+}
+
+func (f *float32Value) Get() interface{} {
+	return float32(*f)
+}
+
+func (f *float32Value) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 32)
+}
+
+// arrayInt8 implements flag.Value, flag.Getter interfaces.
+type arrayInt8 []int8
+
+func newArrayInt8(val []int8, p *[]int8) *arrayInt8 {
+	*p = val
+	return (*arrayInt8)(p)
+}
+
+func (i *arrayInt8) Set(val string) error {
+	*i = []int8{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseInt(currStr, 8, []int8{})
+			if err != nil {
+				return err
+			}
+			*i = append(*i, int8(curr))
+		}
+	}
+	return nil
+}
+
+func (i *arrayInt8) Get() interface{} {
+	return []int8(*i)
+}
+
+func (i *arrayInt8) String() string {
+	var arrStr []string
+	for _, curr := range *i {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// arrayInt16 implements flag.Value, flag.Getter interfaces.
+type arrayInt16 []int16
+
+func newArrayInt16(val []int16, p *[]int16) *arrayInt16 {
+	*p = val
+	return (*arrayInt16)(p)
+}
+
+func (i *arrayInt16) Set(val string) error {
+	*i = []int16{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseInt(currStr, 16, []int16{})
+			if err != nil {
+				return err
+			}
+			*i = append(*i, int16(curr))
+		}
+	}
+	return nil
+}
+
+func (i *arrayInt16) Get() interface{} {
+	return []int16(*i)
+}
+
+func (i *arrayInt16) String() string {
+	var arrStr []string
+	for _, curr := range *i {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// arrayInt32 implements flag.Value, flag.Getter interfaces.
+type arrayInt32 []int32
+
+func newArrayInt32(val []int32, p *[]int32) *arrayInt32 {
+	*p = val
+	return (*arrayInt32)(p)
+}
+
+func (i *arrayInt32) Set(val string) error {
+	*i = []int32{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseInt(currStr, 32, []int32{})
+			if err != nil {
+				return err
+			}
+			*i = append(*i, int32(curr))
+		}
+	}
+	return nil
+}
+
+func (i *arrayInt32) Get() interface{} {
+	return []int32(*i)
+}
+
+func (i *arrayInt32) String() string {
+	var arrStr []string
+	for _, curr := range *i {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// arrayUint8 implements flag.Value, flag.Getter interfaces.
+type arrayUint8 []uint8
+
+func newArrayUint8(val []uint8, p *[]uint8) *arrayUint8 {
+	*p = val
+	return (*arrayUint8)(p)
+}
+
+func (u *arrayUint8) Set(val string) error {
+	*u = []uint8{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseUint(currStr, 8, []uint8{})
+			if err != nil {
+				return err
+			}
+			*u = append(*u, uint8(curr))
+		}
+	}
+	return nil
+}
+
+func (u *arrayUint8) Get() interface{} {
+	return []uint8(*u)
+}
+
+func (u *arrayUint8) String() string {
+	var arrStr []string
+	for _, curr := range *u {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// arrayUint16 implements flag.Value, flag.Getter interfaces.
+type arrayUint16 []uint16
+
+func newArrayUint16(val []uint16, p *[]uint16) *arrayUint16 {
+	*p = val
+	return (*arrayUint16)(p)
+}
+
+func (u *arrayUint16) Set(val string) error {
+	*u = []uint16{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseUint(currStr, 16, []uint16{})
+			if err != nil {
+				return err
+			}
+			*u = append(*u, uint16(curr))
+		}
+	}
+	return nil
+}
+
+func (u *arrayUint16) Get() interface{} {
+	return []uint16(*u)
+}
+
+func (u *arrayUint16) String() string {
+	var arrStr []string
+	for _, curr := range *u {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// arrayUint32 implements flag.Value, flag.Getter interfaces.
+type arrayUint32 []uint32
+
+func newArrayUint32(val []uint32, p *[]uint32) *arrayUint32 {
+	*p = val
+	return (*arrayUint32)(p)
+}
+
+func (u *arrayUint32) Set(val string) error {
+	*u = []uint32{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseUint(currStr, 32, []uint32{})
+			if err != nil {
+				return err
+			}
+			*u = append(*u, uint32(curr))
+		}
+	}
+	return nil
+}
+
+func (u *arrayUint32) Get() interface{} {
+	return []uint32(*u)
+}
+
+func (u *arrayUint32) String() string {
+	var arrStr []string
+	for _, curr := range *u {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// arrayFloat32 implements flag.Value, flag.Getter interfaces.
+type arrayFloat32 []float32
+
+func newArrayFloat32(val []float32, p *[]float32) *arrayFloat32 {
+	*p = val
+	return (*arrayFloat32)(p)
+}
+
+func (f *arrayFloat32) Set(val string) error {
+	*f = []float32{}
+	if val != "" {
+		arrStr := strings.Split(val, comma)
+		for _, currStr := range arrStr {
+			curr, err := parseFloat(currStr, 32, []float32{})
+			if err != nil {
+				return err
+			}
+			*f = append(*f, float32(curr))
+		}
+	}
+	return nil
+}
+
+func (f *arrayFloat32) Get() interface{} {
+	return []float32(*f)
+}
+
+func (f *arrayFloat32) String() string {
+	var arrStr []string
+	for _, curr := range *f {
+		arrStr = append(arrStr, fmt.Sprint(curr))
+	}
+	return strings.Join(arrStr, comma)
+}
+
+// parseInt parses the integer that fits into bitSize bits (t is the type of
+// the value used in error messages).
+func parseInt(val string, bitSize int, t interface{}) (int64, error) {
+	v, err := strconv.ParseInt(val, 10, bitSize)
+	if err != nil {
+		return 0, numError(err, val, bitSize, t)
+	}
+	return v, nil
+}
+
+// parseUint parses the unsigned integer that fits into bitSize bits (t is the
+// type of the value used in error messages).
+func parseUint(val string, bitSize int, t interface{}) (uint64, error) {
+	v, err := strconv.ParseUint(val, 10, bitSize)
+	if err != nil {
+		return 0, numError(err, val, bitSize, t)
+	}
+	return v, nil
+}
+
+// parseFloat parses the floating-point number that fits into bitSize bits (t
+// is the type of the value used in error messages).
+func parseFloat(val string, bitSize int, t interface{}) (float64, error) {
+	v, err := strconv.ParseFloat(val, bitSize)
+	if err != nil {
+		return 0, numError(err, val, bitSize, t)
+	}
+	return v, nil
+}
+
+// numError converts strconv error to ParseError.
+func numError(err error, val string, bitSize int, t interface{}) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return errOutOfRange(val, t, bitSize)
+	}
+	return errCantUse(val, t)
+}
@@ -23,6 +23,20 @@ var (
 	_ flag.Getter = &arrayDuration{}
 	_ flag.Value  = &arrayString{}
 	_ flag.Getter = &arrayString{}
+	_ flag.Getter = new(int8Value)
+	_ flag.Getter = new(int16Value)
+	_ flag.Getter = new(int32Value)
+	_ flag.Getter = new(uint8Value)
+	_ flag.Getter = new(uint16Value)
+	_ flag.Getter = new(uint32Value)
+	_ flag.Getter = new(float32Value)
+	_ flag.Getter = &arrayInt8{}
+	_ flag.Getter = &arrayInt16{}
+	_ flag.Getter = &arrayInt32{}
+	_ flag.Getter = &arrayUint8{}
+	_ flag.Getter = &arrayUint16{}
+	_ flag.Getter = &arrayUint32{}
+	_ flag.Getter = &arrayFloat32{}
 )
 
 func Test_arrayInt(t *testing.T) {
@@ -212,3 +226,41 @@ func Test_arrayString(t *testing.T) {
 		})
 	})
 }
+
+func Test_sizedNumbers(t *testing.T) {
+	type testCase struct {
+		title string
+		value flag.Getter
+		in    string
+		out   interface{}
+		str   string
+		err   error
+	}
+	var cases = []testCase{
+		{"int8", newInt8Value(0, new(int8)), "-128", int8(-128), "-128", nil},
+		{"int8 overflow", newInt8Value(0, new(int8)), "128", int8(0), "0", errOutOfRange("128", int8(0), 8)},
+		{"int16", newInt16Value(0, new(int16)), "32767", int16(32767), "32767", nil},
+		{"int32 invalid", newInt32Value(0, new(int32)), "x", int32(0), "0", errCantUse("x", int32(0))},
+		{"uint8", newUint8Value(0, new(uint8)), "255", uint8(255), "255", nil},
+		{"uint16 overflow", newUint16Value(0, new(uint16)), "70000", uint16(0), "0", errOutOfRange("70000", uint16(0), 16)},
+		{"uint32 negative", newUint32Value(0, new(uint32)), "-1", uint32(0), "0", errCantUse("-1", uint32(0))},
+		{"float32", newFloat32Value(0, new(float32)), "0.5", float32(0.5), "0.5", nil},
+		{"float32 overflow", newFloat32Value(0, new(float32)), "1e39", float32(0), "0", errOutOfRange("1e39", float32(0), 32)},
+		{"[]int8", newArrayInt8(nil, new([]int8)), "-1,1", []int8{-1, 1}, "-1,1", nil},
+		{"[]int16 overflow", newArrayInt16(nil, new([]int16)), "1,40000", []int16{1}, "1", errOutOfRange("40000", []int16{}, 16)},
+		{"[]int32", newArrayInt32(nil, new([]int32)), "", []int32{}, "", nil},
+		{"[]uint8", newArrayUint8(nil, new([]uint8)), "0,255", []uint8{0, 255}, "0,255", nil},
+		{"[]uint16 invalid", newArrayUint16(nil, new([]uint16)), "1,x", []uint16{1}, "1", errCantUse("x", []uint16{})},
+		{"[]uint32", newArrayUint32(nil, new([]uint32)), "4294967295", []uint32{4294967295}, "4294967295", nil},
+		{"[]float32", newArrayFloat32(nil, new([]float32)), "0.5,1.5", []float32{0.5, 1.5}, "0.5,1.5", nil},
+	}
+	Convey("test sized number types", t, func() {
+		for _, c := range cases {
+			Convey(c.title, func() {
+				So(c.value.Set(c.in), ShouldResemble, c.err)
+				So(c.value.Get(), ShouldResemble, c.out)
+				So(c.value.String(), ShouldEqual, c.str)
+			})
+		}
+	})
+}
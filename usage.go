@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// configFlagUsage is a usage of the config file flag
+const configFlagUsage = "path to the config file (JSON, YAML, TOML, INI or .env)"
+
+// usage returns the description of the field provided with "usage" (or "desc")
+// struct tag.
+func (f *fieldInfo) usage() string {
+	if usage := f.tag.Get(keyUsageTag); usage != "" {
+		return usage
+	}
+	return f.tag.Get(keyDescTag)
+}
+
+// details returns the env variable name, the default value, the required
+// marker and the allowed values of the field.
+func (f *fieldInfo) details() string {
+	details := []string{"env: " + f.Env}
+	if defValue := f.tag.Get(keyDefaultTag); defValue != "" {
+		details = append(details, fmt.Sprintf("default: %q", defValue))
+	}
+	if f.required {
+		details = append(details, "required")
+	}
+	if oneOf := strings.Fields(f.tag.Get(keyOneOfTag)); len(oneOf) != 0 {
+		details = append(details, "allowed: "+strings.Join(oneOf, ", "))
+	}
+	return "[" + strings.Join(details, "] [") + "]"
+}
+
+// printUsage prints the usage of the config flags grouped by nested structs
+// (used as the Usage func of the FlagSet).
+func (s *loadState) printUsage() {
+	var (
+		w      = s.writer()
+		groups []string
+		fields = make(map[string][]*fieldInfo)
+	)
+	for _, f := range s.fields {
+		group := ""
+		if i := strings.LastIndex(f.Path, "."); i >= 0 {
+			group = f.Path[:i]
+		}
+		if _, ok := fields[group]; !ok {
+			groups = append(groups, group)
+		}
+		fields[group] = append(fields[group], f)
+	}
+	fmt.Fprintf(w, "Usage of %s:\n", s.flagSet.Name())
+	if s.configFlag != "" {
+		fmt.Fprintf(w, "  --%s string\n    \t%s\n", s.configFlag, configFlagUsage)
+	}
+	for _, group := range groups {
+		if group != "" {
+			fmt.Fprintf(w, "\n%s:\n", group)
+		}
+		for _, f := range fields[group] {
+			fmt.Fprintf(w, "  --%s %s\n", f.Flag, f.typ)
+			if usage := f.usage(); usage != "" {
+				fmt.Fprintf(w, "    \t%s\n", strings.ReplaceAll(usage, "\n", "\n    \t"))
+			}
+			fmt.Fprintf(w, "    \t%s\n", f.details())
+		}
+	}
+}
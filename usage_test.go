@@ -0,0 +1,54 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Usage(t *testing.T) {
+	type testConfig struct {
+		Name     string `default:"app" usage:"application name"`
+		Mode     string `default:"dev" oneof:"dev prod" desc:"running mode"`
+		Database struct {
+			Host    string        `required:"true" usage:"database host"`
+			Timeout time.Duration `default:"5s"`
+		}
+		Debug bool `usage:"enable debug mode\nwith verbose logging"`
+	}
+	Convey("Usage", t, func() {
+		var buf bytes.Buffer
+		l := NewLoader(
+			WithEnvPrefix("APP"),
+			WithFlagSetName("app"),
+			WithArgs([]string{"-h"}),
+			WithLookupEnv(mapEnv(nil)),
+			WithOutput(&buf),
+		)
+		So(l.Load(new(testConfig)), ShouldEqual, flag.ErrHelp)
+		So(buf.String(), ShouldEqual, `Usage of app:
+  --config string
+    	path to the config file (JSON, YAML, TOML, INI or .env)
+  --name string
+    	application name
+    	[env: APP_NAME] [default: "app"]
+  --mode string
+    	running mode
+    	[env: APP_MODE] [default: "dev"] [allowed: dev, prod]
+  --debug bool
+    	enable debug mode
+    	with verbose logging
+    	[env: APP_DEBUG]
+
+Database:
+  --database-host string
+    	database host
+    	[env: APP_DATABASE_HOST] [required]
+  --database-timeout time.Duration
+    	[env: APP_DATABASE_TIMEOUT] [default: "5s"]
+`)
+	})
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// validation rules (struct tags) in order they are checked
+var rules = []string{keyMinTag, keyMaxTag, keyMinLenTag, keyMaxLenTag, keyOneOfTag, keyPatternTag}
+
+// validate checks the values of all the config fields (that have no errors yet)
+// against the validation rules provided with struct tags.
+func (s *loadState) validate() {
+	for _, f := range s.fields {
+		if f.failed {
+			continue
+		}
+		rv := f.rv
+		if rv.Kind() == reflect.Ptr && f.text != f.typ {
+			// pointer leaf is validated only if it is set
+			if rv.IsNil() {
+				continue
+			}
+			rv = rv.Elem()
+		}
+		for _, rule := range rules {
+			param, ok := f.tag.Lookup(rule)
+			if !ok {
+				continue
+			}
+			valid, err := check(rv, rule, param)
+			if err != nil {
+				s.fail(f, err)
+				continue
+			}
+			if !valid {
+				value := formatValue(rv)
+				if f.secret {
+					value = secretMask
+				}
+				s.errs = append(s.errs, &ValidationError{Field: f.Field, Rule: rule, Param: param, Value: value})
+			}
+		}
+	}
+}
+
+// check validates the value against the rule.
+func check(v reflect.Value, rule, param string) (bool, error) {
+	switch rule {
+	case keyMinTag, keyMaxTag:
+		cmp, err := compareNumber(v, param)
+		if err != nil {
+			return false, errInvalidRule(rule, param, v.Type())
+		}
+		if rule == keyMinTag {
+			return cmp >= 0, nil
+		}
+		return cmp <= 0, nil
+	case keyMinLenTag, keyMaxLenTag:
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			return false, errInvalidRule(rule, param, v.Type())
+		}
+		var length int
+		switch v.Kind() {
+		case reflect.String:
+			length = utf8.RuneCountInString(v.String())
+		case reflect.Slice, reflect.Map:
+			length = v.Len()
+		default:
+			return false, errInvalidRule(rule, param, v.Type())
+		}
+		if rule == keyMinLenTag {
+			return length >= limit, nil
+		}
+		return length <= limit, nil
+	case keyOneOfTag:
+		allowed := strings.Fields(param)
+		return eachString(v, rule, param, func(s string) bool {
+			for _, value := range allowed {
+				if s == value {
+					return true
+				}
+			}
+			return false
+		})
+	case keyPatternTag:
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return false, errInvalidRule(rule, param, v.Type())
+		}
+		return eachString(v, rule, param, re.MatchString)
+	default:
+		return true, nil
+	}
+}
+
+// eachString checks the string (or every string of the slice) with provided
+// func.
+func eachString(v reflect.Value, rule, param string, fn func(string) bool) (bool, error) {
+	switch {
+	case v.Kind() == reflect.String:
+		return fn(v.String()), nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < v.Len(); i++ {
+			if !fn(v.Index(i).String()) {
+				return false, nil
+			}
+		}
+		return true, nil
+	default:
+		return false, errInvalidRule(rule, param, v.Type())
+	}
+}
+
+// compareNumber compares the numeric value with the param (parsed according to
+// the type of the value), returns -1, 0 or +1.
+func compareNumber(v reflect.Value, param string) (int, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var (
+			limit int64
+			err   error
+		)
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			d, err = time.ParseDuration(param)
+			limit = int64(d)
+		} else {
+			limit, err = strconv.ParseInt(param, 10, 64)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Int() < limit, v.Int() > limit), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		limit, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Uint() < limit, v.Uint() > limit), nil
+	case reflect.Float32, reflect.Float64:
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Float() < limit, v.Float() > limit), nil
+	default:
+		return 0, fmt.Errorf("not a number")
+	}
+}
+
+// compare converts the results of comparison to -1, 0 or +1.
+func compare(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// formatValue formats the value of the field (slices are formatted as
+// comma-separated lists).
+func formatValue(v reflect.Value) string {
+	switch {
+	case isCustom(v.Type()):
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatText(v.Index(i))
+		}
+		return strings.Join(items, comma)
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = fmt.Sprint(key.Interface()) + "=" + formatText(v.MapIndex(key))
+		}
+		sort.Strings(items)
+		return strings.Join(items, comma)
+	}
+	return formatText(v)
+}
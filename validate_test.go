@@ -0,0 +1,113 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_check(t *testing.T) {
+	type testCase struct {
+		title string
+		value interface{}
+		rule  string
+		param string
+		valid bool
+		err   error
+	}
+	var cases = []testCase{
+		{"min int", 1, keyMinTag, "1", true, nil},
+		{"min int fails", 0, keyMinTag, "1", false, nil},
+		{"max int", -5, keyMaxTag, "-5", true, nil},
+		{"max uint fails", uint(70000), keyMaxTag, "65535", false, nil},
+		{"min float", 0.5, keyMinTag, "0.25", true, nil},
+		{"max float fails", 0.5, keyMaxTag, "0.25", false, nil},
+		{"min duration", time.Second, keyMinTag, "100ms", true, nil},
+		{"max duration fails", time.Minute, keyMaxTag, "30s", false, nil},
+		{"invalid min", 1, keyMinTag, "one", false, errInvalidRule(keyMinTag, "one", reflect.TypeOf(1))},
+		{"min of string", "text", keyMinTag, "1", false, errInvalidRule(keyMinTag, "1", reflect.TypeOf(""))},
+		{"minlen of string", "тест", keyMinLenTag, "4", true, nil},
+		{"maxlen of string fails", "test", keyMaxLenTag, "3", false, nil},
+		{"minlen of slice fails", []int{}, keyMinLenTag, "1", false, nil},
+		{"maxlen of slice", []int{1, 2}, keyMaxLenTag, "2", true, nil},
+		{"maxlen of int", 1, keyMaxLenTag, "2", false, errInvalidRule(keyMaxLenTag, "2", reflect.TypeOf(1))},
+		{"oneof", "prod", keyOneOfTag, "dev prod", true, nil},
+		{"oneof fails", "test", keyOneOfTag, "dev prod", false, nil},
+		{"oneof slice fails", []string{"dev", "test"}, keyOneOfTag, "dev prod", false, nil},
+		{"oneof of int", 1, keyOneOfTag, "1 2", false, errInvalidRule(keyOneOfTag, "1 2", reflect.TypeOf(1))},
+		{"pattern", "app-1", keyPatternTag, "^[a-z]+-[0-9]$", true, nil},
+		{"pattern fails", "app", keyPatternTag, "^[a-z]+-[0-9]$", false, nil},
+		{"pattern slice", []string{"a1", "b2"}, keyPatternTag, "[0-9]", true, nil},
+		{"invalid pattern", "app", keyPatternTag, "[", false, errInvalidRule(keyPatternTag, "[", reflect.TypeOf(""))},
+	}
+	Convey("Validation rules", t, func() {
+		for _, c := range cases {
+			Convey(c.title, func() {
+				valid, err := check(reflect.ValueOf(c.value), c.rule, c.param)
+				So(valid, ShouldEqual, c.valid)
+				So(err, ShouldResemble, c.err)
+			})
+		}
+	})
+}
+
+func Test_Loader_Validate(t *testing.T) {
+	type testConfig struct {
+		Port    int           `default:"8080" min:"1" max:"65535"`
+		Timeout time.Duration `default:"5s" min:"1s"`
+		Mode    string        `default:"dev" oneof:"dev prod"`
+		Hosts   []string      `minlen:"1" pattern:"^[a-z.]+$"`
+		Name    string        `required:"true" minlen:"3"`
+	}
+	Convey("Validation", t, func() {
+		Convey("valid values", func() {
+			conf := new(testConfig)
+			l := NewLoader(WithArgs([]string{"-hosts", "a.local,b.local", "-name", "app"}), WithLookupEnv(mapEnv(nil)))
+			So(l.Load(conf), ShouldBeNil)
+		})
+		Convey("all the failures are reported with their sources", func() {
+			l := NewLoader(
+				WithArgs([]string{"-port", "70000", "-mode", "test", "-hosts", "A.local"}),
+				WithLookupEnv(mapEnv(map[string]string{"TIMEOUT": "10ms"})),
+			)
+			So(l.Load(new(testConfig)), ShouldResemble, Errors{
+				errMissingRequired(Field{Path: "Name", Flag: "name", Env: "NAME"}),
+				&ValidationError{Field: Field{Path: "Port", Flag: "port", Env: "PORT", Source: SourceFlag}, Rule: keyMaxTag, Param: "65535", Value: "70000"},
+				&ValidationError{Field: Field{Path: "Timeout", Flag: "timeout", Env: "TIMEOUT", Source: SourceEnv}, Rule: keyMinTag, Param: "1s", Value: "10ms"},
+				&ValidationError{Field: Field{Path: "Mode", Flag: "mode", Env: "MODE", Source: SourceFlag}, Rule: keyOneOfTag, Param: "dev prod", Value: "test"},
+				&ValidationError{Field: Field{Path: "Hosts", Flag: "hosts", Env: "HOSTS", Source: SourceFlag}, Rule: keyPatternTag, Param: "^[a-z.]+$", Value: "A.local"},
+			})
+		})
+		Convey("unset value is validated too", func() {
+			conf := &struct {
+				Hosts []string `minlen:"1"`
+			}{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldResemble, Errors{
+				&ValidationError{Field: Field{Path: "Hosts", Flag: "hosts", Env: "HOSTS"}, Rule: keyMinLenTag, Param: "1", Value: ""},
+			})
+		})
+		Convey("values of secret fields are masked", func() {
+			conf := &struct {
+				Password string `secret:"true" minlen:"12"`
+			}{}
+			err := NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(map[string]string{"PASSWORD": "hunter2"}))).Load(conf)
+			So(err, ShouldResemble, Errors{&ValidationError{
+				Field: Field{Path: "Password", Flag: "password", Env: "PASSWORD", Source: SourceEnv},
+				Rule:  keyMinLenTag,
+				Param: "12",
+				Value: secretMask,
+			}})
+			So(err.Error(), ShouldEqual, "field [Password]: value [******] length must be at least [12] (env PASSWORD)")
+		})
+		Convey("invalid tag", func() {
+			conf := &struct {
+				Name string `max:"10"`
+			}{}
+			So(NewLoader(WithArgs(nil), WithLookupEnv(mapEnv(nil))).Load(conf), ShouldResemble, Errors{
+				&FieldError{Field: Field{Path: "Name", Flag: "name", Env: "NAME"}, Err: errInvalidRule(keyMaxTag, "10", reflect.TypeOf(""))},
+			})
+		})
+	})
+}
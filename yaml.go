@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML decodes YAML config file.
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var root map[string]interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return normalizeYAML(root).(map[string]interface{}), nil
+}
+
+// normalizeYAML recursively converts mappings with non-string keys into
+// objects with string keys (so they can be handled like any other tree).
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}